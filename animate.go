@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/solve"
+)
+
+// delay between the frames of -animate, 0 if not animating
+//
+// the flag can be given on its own or with a delay in milliseconds, as in -animate=100
+type animation time.Duration
+
+// delay of a bare -animate
+const defaultFrame = 200 * time.Millisecond
+
+func (a *animation) String() string { return strconv.Itoa(int(time.Duration(*a) / time.Millisecond)) }
+
+func (a *animation) IsBoolFlag() bool { return true }
+
+func (a *animation) Set(s string) error {
+	switch s {
+	case "true":
+		*a = animation(defaultFrame)
+	case "false":
+		*a = 0
+	default:
+		ms, err := strconv.Atoi(s)
+		if err != nil || ms < 0 {
+			return fmt.Errorf("invalid delay %q", s)
+		}
+		*a = animation(time.Duration(ms) * time.Millisecond)
+	}
+	return nil
+}
+
+// replays the steps of t on b, re-rendering the board in place after each step with the changed cell highlighted
+func animate(b board.Board, t solve.Trace, delay time.Duration, th *board.Theme) {
+	b.Render(os.Stdout, board.Style{Theme: th})
+
+	for _, st := range t {
+		time.Sleep(delay)
+		b.Fill(st.Coord, st.Value)
+		// move the cursor back to the top of the board
+		fmt.Printf("\x1b[%dA", 9+3)
+		b.Render(os.Stdout, board.Style{Marks: []coord.Coord{st.Coord}, Theme: th})
+	}
+}
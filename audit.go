@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/solve"
+)
+
+// puzzles an audit counts in parallel before reporting them and saving its progress
+const auditChunk = 1 << 12
+
+// progress of an audit, saved after every chunk so that an interrupted audit resumes after the last saved chunk
+type auditProgress struct {
+	Lines  map[string]int `json:"lines"`  // last line audited, by file
+	Counts map[string]int `json:"counts"` // puzzles audited by outcome: unique, multiple, unsolvable or invalid
+}
+
+// reads the audit progress fn, a missing file or an empty fn is a fresh audit
+func loadProgress(fn string) (auditProgress, error) {
+	p := auditProgress{Lines: map[string]int{}, Counts: map[string]int{}}
+	if fn == "" {
+		return p, nil
+	}
+	f, err := os.Open(fn)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return p, nil
+	case err != nil:
+		return p, err
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&p); err != nil {
+		return p, fmt.Errorf("%s: %w", fn, err)
+	}
+	if p.Lines == nil {
+		p.Lines = map[string]int{}
+	}
+	if p.Counts == nil {
+		p.Counts = map[string]int{}
+	}
+	return p, nil
+}
+
+// writes p to fn through a temporary file, so an interrupted write leaves the previous progress in place
+func (p auditProgress) save(fn string) error {
+	if fn == "" {
+		return nil
+	}
+	err := writeFile(fn+".tmp", func(w io.Writer) error {
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(p)
+	})
+	if err != nil {
+		return err
+	}
+	return os.Rename(fn+".tmp", fn)
+}
+
+// a puzzle of an audit and the problem with it, if any
+type auditJob struct {
+	formats.Entry
+	board board.Board
+	err   error
+}
+
+// counts the solutions of every puzzle of files up to 2 on n parallel workers, reporting the ones without a unique
+// solution and the lines that can't be parsed to w
+//
+// the puzzles are streamed in chunks, after each chunk its problems are reported in input order and the progress is
+// saved to the progress file, unless it's empty. A rerun with the same progress file skips the lines done, a chunk
+// cut short by an interruption is audited again. The totals of the whole audit go to log. Returns the number of
+// puzzles with problems, over every run of the audit.
+//
+// if out is given it gets the outcome of every puzzle as soon as it's counted, instead of the problems going to w. The
+// outcomes of a chunk cut short are written again by the rerun.
+func audit(ctx context.Context, w, log io.Writer, out *jsonLines, files []string, n int, progress string) (int, error) {
+	p, err := loadProgress(progress)
+	if err != nil {
+		return 0, err
+	}
+	n = max(n, 1)
+
+	jobs := []auditJob{}
+	flush := func(fn string) error {
+		if len(jobs) == 0 {
+			return nil
+		}
+		countSolutions(ctx, jobs, n, out)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if out != nil {
+			if err := out.error(); err != nil {
+				return err
+			}
+		}
+		for _, j := range jobs {
+			o := auditOutcome(j.err)
+			p.Counts[o]++
+			if o == "unique" || out != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "%s:%d: %s\n", j.File, j.Line, j.err); err != nil {
+				return err
+			}
+		}
+		p.Lines[fn] = jobs[len(jobs)-1].Line
+		jobs = jobs[:0]
+		return p.save(progress)
+	}
+
+	for _, fn := range files {
+		done := p.Lines[fn]
+		err := readPuzzles(fn, func(e formats.Entry, b board.Board, err error) error {
+			if e.Line <= done {
+				return nil
+			}
+			jobs = append(jobs, auditJob{Entry: e, board: b, err: err})
+			if len(jobs) < auditChunk {
+				return nil
+			}
+			return flush(fn)
+		})
+		if err == nil {
+			err = flush(fn)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	total := 0
+	for _, c := range p.Counts {
+		total += c
+	}
+	fmt.Fprintf(log, "%d puzzles audited\n", total)
+	for _, k := range []string{"unique", "multiple", "unsolvable", "invalid"} {
+		if c := p.Counts[k]; c > 0 {
+			fmt.Fprintf(log, "%-18s %8d\n", k, c)
+		}
+	}
+	return total - p.Counts["unique"], nil
+}
+
+// the outcome of a puzzle whose solutions were counted with the error err: unique, multiple, unsolvable or invalid
+func auditOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "unique"
+	case errors.Is(err, solve.ErrMultiple):
+		return "multiple"
+	case errors.Is(err, solve.ErrUnsolvable):
+		return "unsolvable"
+	}
+	return "invalid"
+}
+
+// counts the solutions of the puzzles of jobs on n workers, setting the error of the jobs without a unique solution
+// and writing the outcome of each job to out unless it's nil
+func countSolutions(ctx context.Context, jobs []auditJob, n int, out *jsonLines) {
+	next := make(chan int)
+	wg := sync.WaitGroup{}
+	for range min(n, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				j := &jobs[i]
+				if j.err == nil {
+					r, err := solve.DLX{Limit: 2}.Solve(ctx, &j.board)
+					if err == nil {
+						err = r.Err()
+					}
+					j.err = err
+				}
+				if out == nil || ctx.Err() != nil {
+					continue
+				}
+				r := jsonResultOf(j.Entry)
+				r.Status = auditOutcome(j.err)
+				if r.Status == "invalid" {
+					r.Error = j.err.Error()
+				}
+				out.write(r)
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// an unauthenticated request
+var errUnauthorized = errors.New("missing or unknown api key")
+
+// decides whether a request of -serve may use the solving endpoints, the hook for the access control of a deployment
+type authenticator interface {
+	authenticate(r *http.Request) error
+}
+
+// api keys a request gives as a bearer token in its Authorization header, or in an X-API-Key header
+type apiKeys [][]byte
+
+// the keys of the file fn, one per line, ignoring empty lines
+func readAPIKeys(fn string) (apiKeys, error) {
+	in, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	ks := apiKeys{}
+	for _, l := range strings.Split(string(in), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			ks = append(ks, []byte(l))
+		}
+	}
+	if len(ks) == 0 {
+		return nil, errors.New(fn + ": no api keys")
+	}
+	return ks, nil
+}
+
+func (ks apiKeys) authenticate(r *http.Request) error {
+	k := r.Header.Get("X-API-Key")
+	if t, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		k = t
+	}
+	if k == "" {
+		return errUnauthorized
+	}
+	// every key is compared, so the time taken doesn't tell how much of a key was right
+	ok := 0
+	for _, want := range ks {
+		ok |= subtle.ConstantTimeCompare([]byte(k), want)
+	}
+	if ok == 0 {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// h behind a, h itself if a is nil
+func authenticated(a authenticator, h http.HandlerFunc) http.HandlerFunc {
+	if a == nil {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := a.authenticate(r); err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			reply(w, http.StatusUnauthorized, jsonResult{Error: err.Error()})
+			return
+		}
+		h(w, r)
+	}
+}
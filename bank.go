@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/gen"
+	"github.com/phaul/sudoku/rate"
+)
+
+// builds a bank of n solution grids from seed on the given number of workers and writes it to fn
+func bankMain(ctx context.Context, fn string, n, workers int, seed int64) error {
+	if fn == "" {
+		return errors.New("-grids needs a -bank file to write")
+	}
+	bk, err := gen.NewBankParallel(ctx, seed, n, workers)
+	if err != nil {
+		return err
+	}
+	return writeFile(fn, bk.Write)
+}
+
+// puzzles -generate tries for a -difficulty before giving up
+const generateTries = 1000
+
+// names of the -difficulty values, easiest first
+func difficultyNames() []string {
+	ns := []string{}
+	for d := rate.Easy; d <= rate.Diabolical; d++ {
+		ns = append(ns, d.String())
+	}
+	return ns
+}
+
+// the puzzle generator of -generate, digging grids of the bank in fn, or random grids of layout l without a bank, with
+// cages of the weights sizes for killer
+func generator(
+	ctx context.Context, fn, variant string, l coord.Layout, sizes []int,
+) (func(rng *rand.Rand, s *gen.Scratch) (puzzle, solution board.Board, err error), error) {
+	if fn == "" && variant == "killer" {
+		return func(rng *rand.Rand, s *gen.Scratch) (board.Board, board.Board, error) {
+			return gen.GenerateKiller(ctx, rng, s, sizes)
+		}, nil
+	}
+	if fn == "" {
+		return func(rng *rand.Rand, s *gen.Scratch) (board.Board, board.Board, error) {
+			return gen.Generate(ctx, rng, l, s)
+		}, nil
+	}
+	if variant != "standard" {
+		return nil, fmt.Errorf("a -bank only holds standard grids, not %s", variant)
+	}
+	r, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	bk, err := gen.ReadBank(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	return func(rng *rand.Rand, s *gen.Scratch) (board.Board, board.Board, error) {
+		return bk.Generate(ctx, rng, s, gen.Limits{})
+	}, nil
+}
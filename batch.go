@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cache"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/solve"
+)
+
+// a puzzle of a batch, and its outcome
+type job struct {
+	formats.Entry
+	board board.Board
+	err   error  // parse error of the line
+	out   string // solution line or the reason there is none, written only by the worker the job is sharded to
+}
+
+// outcome of a puzzle, kept to answer repeats of the same puzzle without solving it again
+type outcome struct {
+	status solve.Status
+	out    string
+}
+
+// number of distinct puzzles a batch remembers the outcome of, shared out between the workers
+const batchCacheSize = 1 << 16
+
+// per worker state of a batch solve
+//
+// workers only touch their own state, the totals are summed up once all of them are done
+type worker struct {
+	status  [solve.Stuck + 1]int                    // number of puzzles by status
+	invalid int                                     // number of lines that couldn't be parsed
+	heat    heatmap                                 // guesses and backtracks of the solves
+	seen    *cache.Cache[[9 * 9]cell.ValT, outcome] // outcomes of the puzzles of the worker, nil to solve every puzzle
+}
+
+// solves the jobs of a shard, writing each outcome to out as soon as it's known unless out is nil
+func (w *worker) run(ctx context.Context, s solve.Solver, jobs []job, out *jsonLines) {
+	for i := range jobs {
+		j := &jobs[i]
+		st := w.solve(ctx, s, j)
+		if out == nil {
+			continue
+		}
+		r := jsonResultOf(j.Entry)
+		r.Status = st.String()
+		switch {
+		case j.err != nil:
+			r.Status, r.Error = "invalid", j.err.Error()
+		case st == solve.Solved:
+			r.Solution = j.out
+		}
+		out.write(r)
+	}
+}
+
+// solves the job j, or takes its outcome from the ones the worker has seen, returning the status of the solve,
+// Unsolvable for a job that couldn't be parsed
+func (w *worker) solve(ctx context.Context, s solve.Solver, j *job) solve.Status {
+	if j.err != nil {
+		w.invalid++
+		j.out = fmt.Sprintf("invalid: %v", j.err)
+		return solve.Unsolvable
+	}
+
+	if w.seen == nil {
+		return w.uncached(ctx, s, j)
+	}
+	if o, ok := w.seen.Get(j.board.Values()); ok {
+		w.status[o.status]++
+		j.out = o.out
+		return o.status
+	}
+	st := w.uncached(ctx, s, j)
+	if st != solve.Aborted {
+		w.seen.Put(j.board.Values(), outcome{status: st, out: j.out})
+	}
+	return st
+}
+
+// solves the job j with s, without looking at the outcomes seen, returning the status of the solve
+func (w *worker) uncached(ctx context.Context, s solve.Solver, j *job) solve.Status {
+	r, _ := s.Solve(ctx, &j.board)
+	w.status[r.Status]++
+	w.heat.add(r.Stats)
+	j.out = r.Status.String()
+	if r.Status == solve.Solved {
+		j.out = r.Solution.Line()
+	}
+	return r.Status
+}
+
+// number of puzzles a batch reads and solves at a time
+const batchChunk = 1 << 12
+
+// solves the puzzles of files with s on n parallel workers
+//
+// the puzzles are streamed in chunks, so memory doesn't grow with the input. A chunk is cut into n contiguous shards,
+// so a worker's jobs stay together in memory and workers don't share cache lines. With fewer puzzles in a chunk than
+// workers and dancing links as s, the spare workers join in on the search of the puzzles, stopping together once a
+// puzzle is decided. A puzzle repeated within the puzzles of a worker is only solved once, unless the heatmap is
+// written. a line is printed to w for every puzzle in input order once its chunk is done, holding the solution or the
+// reason there is none, unless out is given, which gets the result of every puzzle as soon as it's solved; the
+// throughput summary goes to log. The guesses of the logic solver are written to the heatmap file heat unless it's
+// empty. Once ctx is done the chunk being solved is finished and the rest of the input is not read.
+func solveBatch(
+	ctx context.Context, w, log io.Writer, out *jsonLines, files []string, s solve.Solver, n int, heat string,
+) error {
+	ws := make([]worker, max(n, 1))
+	if heat == "" {
+		// a repeat answered from the cache has no guesses, so the heatmap counts every solve
+		for i := range ws {
+			ws[i].seen = cache.New[[9 * 9]cell.ValT, outcome](batchCacheSize/len(ws), 0)
+		}
+	}
+
+	jobs := make([]job, 0, batchChunk)
+	count, used := 0, 1
+	flush := func() error {
+		m, err := solveChunk(ctx, w, out, jobs, s, ws)
+		count, used, jobs = count+len(jobs), max(used, m), jobs[:0]
+		return err
+	}
+	start := time.Now()
+	for _, fn := range files {
+		err := readPuzzles(fn, func(e formats.Entry, b board.Board, err error) error {
+			jobs = append(jobs, job{Entry: e, board: b, err: err})
+			if len(jobs) < batchChunk {
+				return nil
+			}
+			return flush()
+		})
+		if err == nil {
+			err = flush()
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	d := time.Since(start)
+
+	total := worker{}
+	for _, wk := range ws {
+		for st, c := range wk.status {
+			total.status[st] += c
+		}
+		total.invalid += wk.invalid
+		total.heat.add(solve.Stats{Guesses: wk.heat.guesses, Backtracks: wk.heat.backtracks})
+	}
+	fmt.Fprintf(log, "%d puzzles in %v on %d workers, %.0f puzzles/s\n", count, d.Round(time.Millisecond), used,
+		float64(count)/d.Seconds())
+	for st, c := range total.status {
+		if c > 0 {
+			fmt.Fprintf(log, "%-18s %8d\n", solve.Status(st), c)
+		}
+	}
+	if total.invalid > 0 {
+		fmt.Fprintf(log, "%-18s %8d\n", "invalid", total.invalid)
+	}
+	if heat != "" {
+		if err := total.heat.write(heat); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// solves the jobs of a chunk on the workers ws, returning the number of parallel searches it ran
+//
+// the outcomes go to out as in solveBatch, or to w in input order once all of them are known
+func solveChunk(
+	ctx context.Context, w io.Writer, out *jsonLines, jobs []job, s solve.Solver, ws []worker,
+) (int, error) {
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+	n := min(len(ws), len(jobs))
+	per := len(ws) / len(jobs)
+	if d, ok := s.(solve.DLX); ok && per > 1 {
+		s = solve.Parallel{Workers: per, Limit: d.Limit}
+	} else {
+		per = 1
+	}
+
+	wg := sync.WaitGroup{}
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ws[i].run(ctx, s, jobs[i*len(jobs)/n:(i+1)*len(jobs)/n], out)
+		}()
+	}
+	wg.Wait()
+
+	if out != nil {
+		if err := out.error(); err != nil {
+			return n * per, err
+		}
+	} else {
+		for _, j := range jobs {
+			if _, err := fmt.Fprintln(w, j.out); err != nil {
+				return n * per, err
+			}
+		}
+	}
+	return n * per, ctx.Err()
+}
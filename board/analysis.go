@@ -0,0 +1,83 @@
+package board
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// number of empty cells by candidate count, index 0 counts the empty cells without candidates
+type Histogram [10]int
+
+// candidate counts of the empty cells of b
+func (b *Board) Histogram() Histogram {
+	h := Histogram{}
+	for ix, v := range b.values {
+		if v == 0 {
+			h[bits.OnesCount(uint(b.masks[ix]))]++
+		}
+	}
+	return h
+}
+
+// how constrained the empty cells are, from 0 when every empty cell has all 9 candidates to 1 when each has a single
+// one, 1 for a full board
+//
+// tight boards fall to singles, loose ones tend to need search
+func (h Histogram) Tightness() float64 {
+	n, t := 0, 0
+	for c, k := range h {
+		n += k
+		t += k * (9 - max(c, 1))
+	}
+	if n == 0 {
+		return 1
+	}
+	return float64(t) / float64(8*n)
+}
+
+// clue statistics of a puzzle
+type Analysis struct {
+	Clues    int      `json:"clues"`
+	Digits   [9]int   `json:"digits"`   // clues of each digit, digit 1 first
+	Houses   []int    `json:"houses"`   // clues in each house, in the order of the layout's HouseIndices
+	Warnings []string `json:"warnings"` // houses without clues, and digits without clues if there are more than one
+}
+
+// counts the clues of b by digit and by house, and warns about empty houses and missing digits
+//
+// empty houses are legal but unusual enough to be worth a look. With two digits missing from the clues the digits can
+// be swapped in any solution, so the puzzle can't have a unique one.
+func (b *Board) Analyze() Analysis {
+	a := Analysis{Warnings: []string{}}
+
+	for _, v := range b.values {
+		if v != 0 {
+			a.Clues++
+			a.Digits[v-1]++
+		}
+	}
+	for h := range b.layout.AllHouses() {
+		n := 0
+		for _, ix := range b.layout.HouseIndices()[h.ID] {
+			if b.values[ix] != 0 {
+				n++
+			}
+		}
+		a.Houses = append(a.Houses, n)
+		if n == 0 {
+			a.Warnings = append(a.Warnings, fmt.Sprintf("%s has no clues", h))
+		}
+	}
+
+	missing := []string{}
+	for d, n := range a.Digits {
+		if n == 0 {
+			missing = append(missing, fmt.Sprint(d+1))
+		}
+	}
+	if len(missing) > 1 {
+		a.Warnings = append(a.Warnings, fmt.Sprintf("digits %s have no clues", strings.Join(missing, ", ")))
+	}
+	return a
+}
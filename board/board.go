@@ -0,0 +1,271 @@
+// A sudoku board holding the placed values and the candidates of the empty cells, for any layout of houses
+//
+// Example:
+//
+// b := board.New(coord.Standard)
+// b.Give(coord.Coord{X: 0, Y: 0}, 8)
+// b.Render(os.Stdout, board.Style{})
+package board
+
+import (
+	"math/bits"
+
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// the 9 digits as candidates, cell.Everything in the width of the masks of the board
+const everything = cell.MaskT(cell.Everything)
+
+// a sudoku board
+//
+// values and candidates are kept in separate arrays, so the scans over candidates touch as little memory as possible.
+// Boards are values, copying one gives an independent board.
+type Board struct {
+	values [9 * 9]cell.ValT            // values of the cells, 0 for empty
+	masks  [9 * 9]cell.MaskT           // candidates of the empty cells, as in cell.Bits
+	digits [9]coord.Set                // candidate cells of each digit, in sync with masks
+	hash   uint64                      // zobrist hash of values, for cache keys and quick inequality checks
+	placed [coord.MaxHouses]cell.MaskT // digits placed in each house of the layout, as in cell.Bits
+	given  [9 * 9]bool                 // cells filled in as clues of the puzzle
+	locked [9 * 9]bool                 // cells protected from user edits
+	layout coord.Layout                // houses of the board
+}
+
+// an empty board with layout l, every digit is a candidate of every cell
+func New(l coord.Layout) Board {
+	b := Board{layout: l}
+	b.allPossible()
+	return b
+}
+
+// a board with layout l and the non-zero values of v given
+func FromValues(l coord.Layout, v [9 * 9]cell.ValT) Board {
+	b := New(l)
+
+	for ix, val := range v {
+		if val != 0 {
+			b.Give(coord.Itoc(ix), val)
+		}
+	}
+	return b
+}
+
+// a board with layout l and no values, the candidates of the cell at index ix are the bits of ms[ix], as in cell.Mask
+//
+// bits above the 9th are ignored. This is the start of a pencil mark only puzzle, where the candidates are the clues.
+func FromCandidates(l coord.Layout, ms [9 * 9]uint16) Board {
+	b := Board{layout: l}
+	for ix, m := range ms {
+		b.masks[ix] = cell.MaskT(m) & everything
+		for d := range b.digits {
+			if b.masks[ix]&(1<<d) != 0 {
+				b.digits[d].Add(ix)
+			}
+		}
+	}
+	b.recomputePlaced()
+	return b
+}
+
+// houses of the board
+func (b *Board) Layout() coord.Layout { return b.layout }
+
+// values of the cells indexed as in coord.Ctoi, 0 for empty
+func (b *Board) Values() [9 * 9]cell.ValT { return b.values }
+
+// zobrist hash of the values, equal boards have equal hashes
+func (b *Board) Hash() uint64 { return b.hash }
+
+// is the cell at c a clue of the puzzle?
+func (b *Board) IsGiven(c coord.Coord) bool { return b.given[coord.Ctoi(c)] }
+
+// address a board with x, y 0-8 coordinates. 0, 0 is the top left corner and 8, 0 is the top right
+func (b *Board) At(c coord.Coord) cell.Cell {
+	return b.Cell(coord.Ctoi(c))
+}
+
+// At for coordinates from outside of the program, an error wrapping coord.ErrOutOfRange if c is not on the board
+func (b *Board) AtChecked(c coord.Coord) (cell.Cell, error) {
+	ix, err := coord.CtoiChecked(c)
+	if err != nil {
+		return cell.Cell{}, err
+	}
+	return b.Cell(ix), nil
+}
+
+// the cell at index ix, as in coord.Ctoi
+func (b *Board) Cell(ix int) cell.Cell {
+	return cell.OfBits(b.values[ix], b.masks[ix])
+}
+
+// sets all cells to all 9 digits are possible
+func (b *Board) allPossible() {
+	for ix := range b.masks {
+		b.masks[ix] = everything
+	}
+	for d := range b.digits {
+		b.digits[d] = coord.Full()
+	}
+}
+
+// fill a cell in the board at c with v
+//
+// v is eliminated from the peers with a word wide and-not on the candidate bitboard of v, only the peers that
+// actually had v as candidate are visited for updating their masks. A filled cell has its old value taken back first,
+// as Erase would, so the hash and the placed digits of its houses stay right.
+func (b *Board) Fill(c coord.Coord, v cell.ValT) {
+	ix := coord.Ctoi(c)
+	m := cell.MaskT(1) << (v - 1)
+	if b.values[ix] != 0 {
+		b.unfill(ix)
+	}
+
+	for ms := b.masks[ix]; ms != 0; ms &= ms - 1 {
+		b.digits[bits.TrailingZeros(uint(ms))].Remove(ix)
+	}
+	b.values[ix] = v
+	b.masks[ix] = 0
+	b.hash ^= zobrist[ix][v-1]
+
+	hit := b.digits[v-1].And(b.layout.PeerSet(ix))
+	b.digits[v-1] = b.digits[v-1].AndNot(hit)
+	for p := hit.First(); p >= 0; p = hit.First() {
+		b.masks[p] &^= m
+		hit.Remove(p)
+	}
+	for _, h := range b.layout.HousesOf(ix) {
+		b.placed[h] |= m
+	}
+}
+
+// drops v as a candidate of the cell at index ix
+func (b *Board) Drop(ix int, v cell.ValT) {
+	b.masks[ix] &^= 1 << (v - 1)
+	b.digits[v-1].Remove(ix)
+}
+
+// adds v as a candidate of the cell at index ix if it wasn't one, drops it otherwise
+func (b *Board) Toggle(ix int, v cell.ValT) {
+	b.masks[ix] ^= 1 << (v - 1)
+	if b.digits[v-1].Has(ix) {
+		b.digits[v-1].Remove(ix)
+	} else {
+		b.digits[v-1].Add(ix)
+	}
+}
+
+// fill a cell in the board at c with the clue v, locking it against edits
+func (b *Board) Give(c coord.Coord, v cell.ValT) {
+	b.Fill(c, v)
+	b.given[coord.Ctoi(c)] = true
+	b.locked[coord.Ctoi(c)] = true
+}
+
+// clears all cells that are not givens and recomputes the candidates from the givens
+func (b *Board) Reset() {
+	for ix, g := range b.given {
+		if !g {
+			b.values[ix] = 0
+		}
+	}
+	b.RecomputeCandidates()
+}
+
+// derives the candidates of every cell, the placed digits of every house and the hash purely from the placed values
+func (b *Board) RecomputeCandidates() {
+	b.recomputePlaced()
+
+	b.digits = [9]coord.Set{}
+	for ix, v := range b.values {
+		b.masks[ix] = 0
+		if v == 0 {
+			b.masks[ix] = b.allowed(ix)
+		}
+		for ms := b.masks[ix]; ms != 0; ms &= ms - 1 {
+			b.digits[bits.TrailingZeros(uint(ms))].Add(ix)
+		}
+	}
+}
+
+// derives the placed digits of every house and the hash from the placed values
+func (b *Board) recomputePlaced() {
+	b.hash = b.rehash()
+	b.placed = [coord.MaxHouses]cell.MaskT{}
+	for h, ixs := range b.layout.HouseIndices() {
+		for _, ix := range ixs {
+			if v := b.values[ix]; v != 0 {
+				b.placed[h] |= 1 << (v - 1)
+			}
+		}
+	}
+}
+
+// cells where v is still a candidate
+func (b *Board) Positions(v cell.ValT) coord.Set { return b.digits[v-1] }
+
+// every cell is filled, and the killer cages add up to their sums
+func (b *Board) Solved() bool {
+	i := coord.All()
+
+	for i.Next() {
+		if b.At(i.Value().(coord.Coord)).IsEmpty() {
+			return false
+		}
+	}
+	return b.brokenCage() < 0
+}
+
+// there is a cell that has no possible value left but also not filled in, or a killer cage going over its sum or
+// filled without adding up to it
+func (b *Board) Contradicts() bool {
+	i := coord.All()
+
+	for i.Next() {
+		c := b.At(i.Value().(coord.Coord))
+
+		if c.Value == 0 && c.PossibilityCount() == 0 {
+			return true
+		}
+	}
+	return b.brokenCage() >= 0
+}
+
+// the empty cell with the least possibilities
+func (b *Board) Fewest() coord.Coord {
+	r := coord.Coord{}
+	n := 10
+	i := coord.All()
+
+	for i.Next() {
+		c := i.Value().(coord.Coord)
+		if p := b.At(c).PossibilityCount(); b.At(c).IsEmpty() && p < n {
+			r, n = c, p
+		}
+	}
+	return r
+}
+
+// number of filled cells
+func (b *Board) Clues() int {
+	n := 0
+
+	for _, v := range b.values {
+		if v != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// the values of b in the 81 character line format, with '.' for empty cells
+func (b *Board) Line() string {
+	l := [9 * 9]byte{}
+	for ix, v := range b.values {
+		l[ix] = '.'
+		if v != 0 {
+			l[ix] = '0' + byte(v)
+		}
+	}
+	return string(l[:])
+}
@@ -0,0 +1,32 @@
+package board
+
+import "fmt"
+
+// the sum of the values placed in cage k, and whether every cell of it is filled
+func (b *Board) cageSum(k int) (sum int, full bool) {
+	full = true
+	for _, ix := range b.layout.CageIndices(k) {
+		sum += int(b.values[ix])
+		full = full && b.values[ix] != 0
+	}
+	return sum, full
+}
+
+// the first cage whose values go over its sum, or fill it without adding up to it, -1 if there is none
+func (b *Board) brokenCage() int {
+	for k, cg := range b.layout.Cages() {
+		if sum, full := b.cageSum(k); sum > cg.Sum || full && sum != cg.Sum {
+			return k
+		}
+	}
+	return -1
+}
+
+// an error for the broken cage k
+func (b *Board) cageError(k int) error {
+	cg := b.layout.Cages()[k]
+	sum, _ := b.cageSum(k)
+	c := cg.Cells[0]
+	return fmt.Errorf("invalid puzzle: the cage of r%dc%d adds up to %d instead of %d: %w",
+		c.Y+1, c.X+1, sum, cg.Sum, ErrCageSum)
+}
@@ -0,0 +1,95 @@
+package board
+
+import (
+	"github.com/phaul/sudoku/cell"
+)
+
+// a and b hold the same values in every cell
+func Equal(a, b Board) bool {
+	return a.hash == b.hash && a.values == b.values
+}
+
+// a and b are the same puzzle after a validity preserving transformation: transposing, swapping bands or stacks,
+// swapping rows or columns within a band or stack, and relabeling digits
+//
+// only meaningful for the standard layout
+func Equivalent(a, b Board) bool {
+	return a.Canonical() == b.Canonical()
+}
+
+// orders of the 9 rows (or columns) reachable by swapping bands and rows within bands
+var lineOrders = func() [][9]int {
+	ps := [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	orders := [][9]int{}
+
+	for _, band := range ps {
+		for _, p0 := range ps {
+			for _, p1 := range ps {
+				for _, p2 := range ps {
+					o := [9]int{}
+					for i, p := range [3][3]int{p0, p1, p2} {
+						for j := range p {
+							o[i*3+j] = band[i]*3 + p[j]
+						}
+					}
+					orders = append(orders, o)
+				}
+			}
+		}
+	}
+	return orders
+}()
+
+// canonical form of the values of b
+//
+// out of all equivalent grids with digits relabeled in order of first appearance, the lexicographically smallest
+func (b *Board) Canonical() [9 * 9]cell.ValT {
+	best := [9 * 9]cell.ValT{}
+	for i := range best {
+		best[i] = 10
+	}
+
+	v := b.values
+	t := [9 * 9]cell.ValT{}
+	for ix, val := range v {
+		t[ix%9*9+ix/9] = val
+	}
+
+	for _, g := range [][9 * 9]cell.ValT{v, t} {
+		for _, ro := range lineOrders {
+			for _, co := range lineOrders {
+				minimize(&g, &ro, &co, &best)
+			}
+		}
+	}
+	return best
+}
+
+// relabels g with rows and columns in order ro and co, replacing best if the result is smaller
+func minimize(g *[9 * 9]cell.ValT, ro, co *[9]int, best *[9 * 9]cell.ValT) {
+	c := [9 * 9]cell.ValT{}
+	label := [10]cell.ValT{}
+	next := cell.ValT(1)
+	smaller := false
+
+	for ix := range c {
+		x := g[ro[ix/9]*9+co[ix%9]]
+		if x != 0 {
+			if label[x] == 0 {
+				label[x] = next
+				next++
+			}
+			x = label[x]
+		}
+		if !smaller {
+			if x > best[ix] {
+				return
+			}
+			smaller = x < best[ix]
+		}
+		c[ix] = x
+	}
+	if smaller {
+		*best = c
+	}
+}
@@ -0,0 +1,146 @@
+package board
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// locks or unlocks the cell at c against editing
+func (b *Board) SetLocked(c coord.Coord, l bool) {
+	b.locked[coord.Ctoi(c)] = l
+}
+
+// is the cell at c locked against editing?
+func (b *Board) IsLocked(c coord.Coord) bool {
+	return b.locked[coord.Ctoi(c)]
+}
+
+// places v at c as a user edit, refusing to overwrite a locked cell unless forced
+//
+// only the cell and its peers change: v is dropped from the candidates of the peers and the pencil marks of every other
+// cell are kept. Overwriting a value takes the old one out first, as Erase does, and a given forced over is a placed
+// value from then on.
+func (b *Board) Place(c coord.Coord, v cell.ValT, force bool) error {
+	_, err := b.AtChecked(c)
+	switch {
+	case err != nil:
+		return fmt.Errorf("placing %d: %w", v, err)
+	case v < 1 || v > 9:
+		return fmt.Errorf("placing %d at r%dc%d: %w", v, c.Y+1, c.X+1, ErrValue)
+	case b.IsLocked(c) && !force:
+		return fmt.Errorf("placing %d at r%dc%d: %w", v, c.Y+1, c.X+1, ErrLocked)
+	}
+	b.Fill(c, v)
+	b.given[coord.Ctoi(c)] = false
+	return nil
+}
+
+// clears the value at c as a user edit, refusing to clear a locked cell unless forced
+//
+// only the cell and its peers change: the cell gets the candidates its peers allow, and the erased digit is a
+// candidate of the empty peers again unless one of their own peers holds it. The pencil marks of every other cell
+// are kept.
+func (b *Board) Erase(c coord.Coord, force bool) error {
+	if _, err := b.AtChecked(c); err != nil {
+		return fmt.Errorf("erasing: %w", err)
+	}
+	if b.IsLocked(c) && !force {
+		return fmt.Errorf("erasing r%dc%d: %w", c.Y+1, c.X+1, ErrLocked)
+	}
+	ix := coord.Ctoi(c)
+	if !b.Cell(ix).IsEmpty() {
+		b.unfill(ix)
+	}
+	b.given[ix] = false
+	return nil
+}
+
+// takes the value out of the filled cell at ix, the reverse of Fill on the cell and its peers
+func (b *Board) unfill(ix int) {
+	v := b.values[ix]
+	m := cell.MaskT(1) << (v - 1)
+	b.values[ix] = 0
+	b.hash ^= zobrist[ix][v-1]
+
+	// v can be placed in a house more than once by user edits, so the houses are looked at again
+	for _, h := range b.layout.HousesOf(ix) {
+		b.placed[h] = 0
+		for _, p := range b.layout.HouseIndices()[h] {
+			if w := b.values[p]; w != 0 {
+				b.placed[h] |= 1 << (w - 1)
+			}
+		}
+	}
+	b.masks[ix] = b.allowed(ix)
+	for ms := b.masks[ix]; ms != 0; ms &= ms - 1 {
+		b.digits[bits.TrailingZeros(uint(ms))].Add(ix)
+	}
+
+	for _, p := range b.layout.PeerIndices(ix) {
+		if b.values[p] == 0 && b.allowed(p)&m != 0 {
+			b.masks[p] |= m
+			b.digits[v-1].Add(p)
+		}
+	}
+}
+
+// the digits placed in the houses of the cell at ix and in the cells linked with it
+func (b *Board) ruled(ix int) cell.MaskT {
+	m := cell.MaskT(0)
+	for _, h := range b.layout.HousesOf(ix) {
+		m |= b.placed[h]
+	}
+	for _, p := range b.layout.Links(ix) {
+		if v := b.values[p]; v != 0 {
+			m |= 1 << (v - 1)
+		}
+	}
+	return m
+}
+
+// the digits the cell at ix can hold, not placed in its houses and the cells linked with it
+func (b *Board) allowed(ix int) cell.MaskT { return everything &^ b.ruled(ix) }
+
+// toggles the candidate v of the empty cell at c as a user edit, refusing to edit a locked cell
+func (b *Board) ToggleCandidate(c coord.Coord, v cell.ValT) error {
+	x, err := b.AtChecked(c)
+	switch {
+	case err != nil:
+		return fmt.Errorf("toggling %d: %w", v, err)
+	case v < 1 || v > 9:
+		return fmt.Errorf("toggling %d at r%dc%d: %w", v, c.Y+1, c.X+1, ErrValue)
+	case !x.IsEmpty():
+		return fmt.Errorf("toggling %d at r%dc%d: %w", v, c.Y+1, c.X+1, ErrFilled)
+	case b.IsLocked(c):
+		return fmt.Errorf("toggling %d at r%dc%d: %w", v, c.Y+1, c.X+1, ErrLocked)
+	}
+	b.Toggle(coord.Ctoi(c), v)
+	return nil
+}
+
+// drops every candidate of the empty cell at c as a user edit, refusing to edit a locked cell
+func (b *Board) ClearCandidates(c coord.Coord) error {
+	x, err := b.AtChecked(c)
+	switch {
+	case err != nil:
+		return fmt.Errorf("clearing candidates: %w", err)
+	case !x.IsEmpty():
+		return fmt.Errorf("clearing candidates at r%dc%d: %w", c.Y+1, c.X+1, ErrFilled)
+	case b.IsLocked(c):
+		return fmt.Errorf("clearing candidates at r%dc%d: %w", c.Y+1, c.X+1, ErrLocked)
+	}
+	for v := cell.ValT(1); v <= 9; v++ {
+		if x.IsPossible(v) {
+			b.Drop(coord.Ctoi(c), v)
+		}
+	}
+	return nil
+}
+
+// fills in the candidates of every empty cell the placed values allow as a user edit, replacing the pencil marks
+//
+// this is RecomputeCandidates under the name trainer apps give it, it can't fail.
+func (b *Board) AutoFillCandidates() { b.RecomputeCandidates() }
@@ -0,0 +1,128 @@
+package board
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// do a and b have the same values, candidates, candidate bitboards, placed digits and hash
+func sameState(a, b *Board) bool {
+	return a.values == b.values && a.masks == b.masks && a.digits == b.digits && a.placed == b.placed &&
+		a.hash == b.hash
+}
+
+func TestPlaceEraseKeepCandidatesInStep(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, l := range []coord.Layout{coord.Standard, coord.X, coord.Windoku, coord.DisjointGroups} {
+		b := New(l)
+		for range 2000 {
+			c := coord.Itoc(rng.Intn(9 * 9))
+			if rng.Intn(3) == 0 {
+				b.Erase(c, true)
+			} else {
+				// repeated digits in a house are allowed, as a user can place them
+				b.Place(c, cell.ValT(rng.Intn(9)+1), true)
+			}
+			want := b
+			want.RecomputeCandidates()
+			if !sameState(&b, &want) {
+				t.Fatalf("%s: the edits left\n%v\nrecomputing gives\n%v", b.Line(), b.masks, want.masks)
+			}
+		}
+	}
+}
+
+func TestPlaceEraseKeepPencilMarks(t *testing.T) {
+	b := New(coord.Standard)
+	r1c1, r1c2, r9c9 := coord.Coord{X: 0, Y: 0}, coord.Coord{X: 1, Y: 0}, coord.Coord{X: 8, Y: 8}
+
+	// the marks of r9c9 are the user's, it isn't a peer of r1c1
+	if err := b.ClearCandidates(r9c9); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ToggleCandidate(r9c9, 5); err != nil {
+		t.Fatal(err)
+	}
+	// 3 is taken out of r1c2 by hand
+	if err := b.ToggleCandidate(r1c2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Place(r1c1, 7, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.At(r9c9).Mask(); got != 1<<4 {
+		t.Errorf("r9c9 candidates %09b after placing at r1c1, want the 5 marked", got)
+	}
+	if b.At(r1c2).IsPossible(7) {
+		t.Error("7 is a candidate of r1c2 next to a 7")
+	}
+
+	if err := b.Erase(r1c1, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.At(r9c9).Mask(); got != 1<<4 {
+		t.Errorf("r9c9 candidates %09b after erasing r1c1, want the 5 marked", got)
+	}
+	if !b.At(r1c2).IsPossible(7) || b.At(r1c2).IsPossible(3) {
+		t.Errorf("r1c2 candidates %09b after erasing r1c1, want 7 back and 3 still out", b.At(r1c2).Mask())
+	}
+	if got := b.At(r1c1).Mask(); got != cell.Everything {
+		t.Errorf("r1c1 candidates %09b after erasing it, want every digit", got)
+	}
+
+	// a 7 placed elsewhere in row 1 keeps it out of r1c2 once r1c1 is erased
+	b.Place(r1c1, 7, false)
+	b.Place(coord.Coord{X: 5, Y: 0}, 7, false)
+	b.Erase(r1c1, false)
+	if b.At(r1c2).IsPossible(7) || b.At(r1c1).IsPossible(7) {
+		t.Error("7 is a candidate in row 1 next to the 7 at r1c6")
+	}
+}
+
+// filling a filled cell takes the old value back first, leaving the board as if only the new value was filled
+func TestRefillTakesOldValueBack(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 200 {
+		b := New(coord.Standard)
+		for range 20 {
+			b.Fill(coord.Itoc(rng.Intn(9*9)), cell.ValT(rng.Intn(9)+1))
+		}
+		b.RecomputeCandidates()
+		c, old, v := coord.Itoc(rng.Intn(9*9)), cell.ValT(rng.Intn(9)+1), cell.ValT(rng.Intn(9)+1)
+
+		want := b
+		if !want.Cell(coord.Ctoi(c)).IsEmpty() {
+			want.unfill(coord.Ctoi(c))
+		}
+		want.Fill(c, v)
+		b.Fill(c, old)
+		b.Fill(c, v)
+		if !sameState(&b, &want) {
+			t.Fatalf("%s: refilling %d with %d left\n%v\nfilling once gives\n%v", b.Line(), old, v, b.masks, want.masks)
+		}
+	}
+}
+
+func TestForcedPlaceOverGiven(t *testing.T) {
+	b := New(coord.Standard)
+	r1c1 := coord.Coord{X: 0, Y: 0}
+	b.Give(r1c1, 4)
+
+	if err := b.Place(r1c1, 6, false); !errors.Is(err, ErrLocked) {
+		t.Errorf("placing over a given without forcing: %v, want %v", err, ErrLocked)
+	}
+	if err := b.Place(r1c1, 6, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.At(r1c1).Value; got != 6 {
+		t.Errorf("r1c1 holds %d after the forced place, want 6", got)
+	}
+	if b.IsGiven(r1c1) {
+		t.Error("r1c1 is still a given after a forced place over it")
+	}
+}
@@ -0,0 +1,26 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+var (
+	ErrLocked  = errors.New("cell is locked")     // editing a locked cell without forcing it
+	ErrFilled  = errors.New("cell is filled")     // editing the candidates of a filled cell
+	ErrValue   = errors.New("value out of range") // a digit outside of 1-9
+	ErrCageSum = errors.New("cage sum broken")    // the values of a killer cage go over its sum or miss it
+)
+
+// a puzzle that repeats a value in a house, or in cells linked by a rule of the variant
+type InvalidPuzzleError struct {
+	Coord coord.Coord // a cell holding the repeated value
+	Value cell.ValT
+}
+
+func (e *InvalidPuzzleError) Error() string {
+	return fmt.Sprintf("invalid puzzle: r%dc%d repeats %d", e.Coord.Y+1, e.Coord.X+1, e.Value)
+}
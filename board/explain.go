@@ -0,0 +1,98 @@
+package board
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// a placed value ruling out a candidate of a cell
+type Witness struct {
+	Coord  coord.Coord // the peer holding the value
+	Houses []string    // names of the houses shared with the cell
+	Rule   string      // the rule linking the peer with the cell if they share no house, like "a knight's move away"
+}
+
+// why a digit is not a candidate of a cell
+//
+// without witnesses the digit was eliminated by a solving technique or by hand, not by a placed value
+type Elimination struct {
+	Value     cell.ValT
+	Witnesses []Witness
+}
+
+// the candidates of a cell and the reasons for the missing digits
+type Explanation struct {
+	Coord        coord.Coord
+	Value        cell.ValT   // value of a filled cell, 0 for empty
+	Candidates   []cell.ValT // remaining candidates of an empty cell
+	Eliminations []Elimination
+}
+
+// explains the candidates of the cell at c: for every digit that isn't a candidate the peers holding it, and the
+// houses they share with c
+func (b *Board) Explain(c coord.Coord) (Explanation, error) {
+	ix, err := coord.CtoiChecked(c)
+	if err != nil {
+		return Explanation{}, err
+	}
+	e := Explanation{Coord: c, Value: b.values[ix]}
+	if e.Value != 0 {
+		return e, nil
+	}
+
+	for v := cell.ValT(1); v <= 9; v++ {
+		if b.Cell(ix).IsPossible(v) {
+			e.Candidates = append(e.Candidates, v)
+			continue
+		}
+
+		el := Elimination{Value: v}
+		for _, p := range b.layout.PeerIndices(ix) {
+			if b.values[p] != v {
+				continue
+			}
+			w := Witness{Coord: coord.Itoc(p), Rule: b.layout.Rule(ix, p)}
+			for _, h := range b.layout.HousesOf(ix) {
+				if slices.Contains(b.layout.HousesOf(p), h) {
+					w.Houses = append(w.Houses, b.layout.HouseName(h))
+				}
+			}
+			el.Witnesses = append(el.Witnesses, w)
+		}
+		e.Eliminations = append(e.Eliminations, el)
+	}
+	return e, nil
+}
+
+// explanation in words, a line per missing digit
+func (e Explanation) String() string {
+	s := strings.Builder{}
+	at := fmt.Sprintf("r%dc%d", e.Coord.Y+1, e.Coord.X+1)
+	if e.Value != 0 {
+		return fmt.Sprintf("%s holds %d", at, e.Value)
+	}
+
+	fmt.Fprintf(&s, "%s candidates: %v", at, e.Candidates)
+	for _, el := range e.Eliminations {
+		fmt.Fprintf(&s, "\n%d: ", el.Value)
+		if len(el.Witnesses) == 0 {
+			s.WriteString("eliminated without a placed peer")
+			continue
+		}
+		for i, w := range el.Witnesses {
+			if i > 0 {
+				s.WriteString(", ")
+			}
+			if w.Rule != "" {
+				fmt.Fprintf(&s, "placed at r%dc%d %s", w.Coord.Y+1, w.Coord.X+1, w.Rule)
+				continue
+			}
+			fmt.Fprintf(&s, "placed at r%dc%d in %s", w.Coord.Y+1, w.Coord.X+1, strings.Join(w.Houses, " and "))
+		}
+	}
+	return s.String()
+}
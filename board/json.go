@@ -0,0 +1,69 @@
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// a board in json, the cells indexed as in coord.Ctoi
+type boardJSON struct {
+	Values     [9 * 9]cell.ValT `json:"values"`     // 0 for empty
+	Candidates [9 * 9]uint16    `json:"candidates"` // bit v-1 set if v is a candidate of the empty cell, as in cell.Mask
+	Given      [9 * 9]bool      `json:"given"`
+	Locked     [9 * 9]bool      `json:"locked"`
+}
+
+// the values, candidates, givens and locked cells of b as a json object
+//
+// the candidates are written as they are, pencil marks included, so a board with progress on it is restored as it
+// was. The layout is not written.
+func (b Board) MarshalJSON() ([]byte, error) {
+	j := boardJSON{Values: b.values, Given: b.given, Locked: b.locked}
+	for ix, m := range b.masks {
+		j.Candidates[ix] = uint16(m)
+	}
+	return json.Marshal(j)
+}
+
+// restores b from the json MarshalJSON writes, keeping the layout of b or taking coord.Standard for a zero board
+//
+// values out of 0-9, candidates out of 9 bits and values repeated in a house are errors, the candidates of filled cells
+// are ignored.
+func (b *Board) UnmarshalJSON(data []byte) error {
+	j := boardJSON{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	r := Board{layout: b.layout, given: j.Given, locked: j.Locked}
+	if r.layout.IsZero() {
+		r.layout = coord.Standard
+	}
+	for ix, v := range j.Values {
+		c := coord.Itoc(ix)
+		switch {
+		case v > 9:
+			return fmt.Errorf("r%dc%d: %d: %w", c.Y+1, c.X+1, v, ErrValue)
+		case j.Candidates[ix] > cell.Everything:
+			return fmt.Errorf("r%dc%d: candidates %#x out of 9 bits", c.Y+1, c.X+1, j.Candidates[ix])
+		case v != 0:
+			r.values[ix] = v
+		default:
+			r.masks[ix] = cell.MaskT(j.Candidates[ix])
+			for d := range 9 {
+				if r.masks[ix]&(1<<d) != 0 {
+					r.digits[d].Add(ix)
+				}
+			}
+		}
+	}
+	r.recomputePlaced()
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	*b = r
+	return nil
+}
@@ -0,0 +1,47 @@
+package board_test
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+)
+
+var (
+	_ fmt.Stringer             = board.Board{}
+	_ fmt.Formatter            = board.Board{}
+	_ encoding.TextMarshaler   = board.Board{}
+	_ json.Marshaler           = board.Board{}
+	_ encoding.TextUnmarshaler = (*board.Board)(nil)
+	_ json.Unmarshaler         = (*board.Board)(nil)
+)
+
+const puzzle = "4.....8.5.3..........7......2.....6.....8.4......1.......6.3.7.5..2.....1.4......"
+
+// a board value, as the APIs pass it around, prints and marshals the same as a pointer to it
+func TestMarshalValue(t *testing.T) {
+	b := board.New(coord.Standard)
+	if err := b.UnmarshalText([]byte(puzzle)); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(b); got != puzzle {
+		t.Errorf("fmt.Sprint = %q, want %q", got, puzzle)
+	}
+
+	for name, v := range map[string]any{"value": b, "pointer": &b} {
+		text, err := json.Marshal(map[string]any{"board": v})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var back struct{ Board board.Board }
+		if err := json.Unmarshal(text, &back); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !board.Equal(back.Board, b) {
+			t.Errorf("%s: json round trip gave %s, want %s", name, back.Board, b)
+		}
+	}
+}
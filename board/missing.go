@@ -0,0 +1,33 @@
+package board
+
+import (
+	"fmt"
+
+	"github.com/phaul/sudoku/coord"
+)
+
+// digits not yet placed in the house of kind containing the cell ix, as in cell.Mask
+//
+// the placed digits of the houses are maintained by Fill, so this is a lookup
+func (b *Board) missing(n, ix int, kind string) (uint16, error) {
+	if n < 0 || n >= 9 {
+		return 0, fmt.Errorf("%w: %s %d", coord.ErrOutOfRange, kind, n)
+	}
+	for _, h := range b.layout.HousesOf(ix) {
+		if b.layout.Kind(h) == kind {
+			return uint16(everything &^ b.placed[h]), nil
+		}
+	}
+	return 0, fmt.Errorf("layout has no %s", kind)
+}
+
+// digits not yet placed in row r, counting from 0
+func (b *Board) MissingInRow(r int) (uint16, error) { return b.missing(r, r*9, "row") }
+
+// digits not yet placed in column c, counting from 0
+func (b *Board) MissingInColumn(c int) (uint16, error) { return b.missing(c, c, "column") }
+
+// digits not yet placed in box n, counting from 0 left to right, top to bottom
+func (b *Board) MissingInBox(n int) (uint16, error) {
+	return b.missing(n, n/3*27+n%3*3, "box")
+}
@@ -0,0 +1,182 @@
+package board
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// how render lays out a board
+type Style struct {
+	Markdown   bool          // a markdown table with the givens in bold, instead of the text grid
+	Candidates bool          // the text grid with the candidates of every empty cell in a 3x3 block of its own
+	Marks      []coord.Coord // cells highlighted in the text grid
+	Theme      *Theme        // glyphs and colors of the text grid, nil for Plain
+}
+
+// glyphs and colors of the text grid, colors are ANSI SGR parameters like "1;97", empty for the terminal default
+type Theme struct {
+	Empty     string // glyph of an empty cell, a single column wide
+	Given     string // color of the givens
+	Placed    string // color of the values filled in
+	Highlight string // color of the highlighted cells
+}
+
+// built in themes, the colored ones keep to blues and oranges that read the same with red-green color blindness
+var (
+	Plain = Theme{Empty: " ", Highlight: "7"}                                              // no colors, highlights in reverse video
+	Dark  = Theme{Empty: "·", Given: "1;97", Placed: "38;5;117", Highlight: "30;48;5;214"} // for dark backgrounds
+	Light = Theme{Empty: "·", Given: "1;30", Placed: "38;5;25", Highlight: "30;48;5;215"}  // for light backgrounds
+)
+
+// themes by name
+var Themes = map[string]*Theme{"plain": &Plain, "dark": &Dark, "light": &Light}
+
+// writes the board to w in style s
+//
+// the board is rendered in full before writing, so w sees a single write
+func (b *Board) Render(w io.Writer, s Style) error {
+	sb := strings.Builder{}
+	t := s.Theme
+	if t == nil {
+		t = &Plain
+	}
+	switch {
+	case s.Markdown:
+		b.markdownTable(&sb)
+	case s.Candidates:
+		b.candidateGrid(&sb, s.Marks, t)
+	default:
+		b.grid(&sb, s.Marks, t)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// the board in the 81 character line format, as Line
+func (b Board) String() string { return b.Line() }
+
+// formats the board for fmt: %+v is the plain text grid, as Render writes it with the zero Style, %v and %s are the
+// line format and %q is the line format quoted
+func (b Board) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		sb := strings.Builder{}
+		b.grid(&sb, nil, &Plain)
+		io.WriteString(f, sb.String())
+	case verb == 'v' || verb == 's':
+		io.WriteString(f, b.Line())
+	case verb == 'q':
+		fmt.Fprintf(f, "%q", b.Line())
+	default:
+		fmt.Fprintf(f, "%%!%c(board.Board=%s)", verb, b.Line())
+	}
+}
+
+// the board as a text grid in theme t, highlighting the cells in marks
+func (b *Board) grid(sb *strings.Builder, marks []coord.Coord, t *Theme) {
+	i := coord.All()
+
+	for i.Next() {
+		c := i.Value().(coord.Coord)
+		if c.Y%3 == 0 && c.X == 0 {
+			sb.WriteString("+---+---+---\n")
+		}
+		if c.X%3 == 0 {
+			sb.WriteString("|")
+		}
+		v := b.At(c).Value
+		if v == 0 {
+			sb.WriteString(t.Empty)
+		} else {
+			b.value(sb, c, marks, t)
+		}
+		if c.X == 8 {
+			sb.WriteString("|\n")
+		}
+	}
+}
+
+// writes the value of the filled cell at c in the color theme t gives it, highlighted if c is in marks
+func (b *Board) value(sb *strings.Builder, c coord.Coord, marks []coord.Coord, t *Theme) {
+	color := t.Placed
+	switch {
+	case slices.Contains(marks, c):
+		color = t.Highlight
+	case b.given[coord.Ctoi(c)]:
+		color = t.Given
+	}
+	if color == "" {
+		fmt.Fprint(sb, b.At(c).Value)
+	} else {
+		fmt.Fprintf(sb, "\x1b[%sm%d\x1b[0m", color, b.At(c).Value)
+	}
+}
+
+// the board as a text grid of 3 lines per row in theme t, like the pencil mark grids of Simple Sudoku and HoDoKu
+//
+// an empty cell is a 3x3 block of its candidates, digit d in line (d-1)/3 and column (d-1)%3 of the block and blanks
+// in place of the others. a filled cell has its value in the middle of the block. The cells in marks are highlighted.
+func (b *Board) candidateGrid(sb *strings.Builder, marks []coord.Coord, t *Theme) {
+	const rule = "+-------------+-------------+-------------+\n"
+	for y := range 9 {
+		if y%3 == 0 {
+			sb.WriteString(rule)
+		} else {
+			sb.WriteString("|             |             |             |\n")
+		}
+		for line := range 3 {
+			for x := range 9 {
+				if x%3 == 0 {
+					sb.WriteString("| ")
+				}
+				c := coord.Itoc(y*9 + x)
+				for col := range 3 {
+					d := cell.ValT(line*3 + col + 1)
+					switch x := b.At(c); {
+					case !x.IsEmpty() && line == 1 && col == 1:
+						b.value(sb, c, marks, t)
+					case !x.IsEmpty() || !x.IsPossible(d):
+						sb.WriteString(" ")
+					case slices.Contains(marks, c) && t.Highlight != "":
+						fmt.Fprintf(sb, "\x1b[%sm%d\x1b[0m", t.Highlight, d)
+					default:
+						fmt.Fprint(sb, d)
+					}
+				}
+				sb.WriteString(" ")
+			}
+			sb.WriteString("|\n")
+		}
+	}
+	sb.WriteString(rule)
+}
+
+// the board as a markdown table with givens in bold
+func (b *Board) markdownTable(sb *strings.Builder) {
+	sb.WriteString("|   | c1 | c2 | c3 | c4 | c5 | c6 | c7 | c8 | c9 |\n")
+	sb.WriteString("|---" + strings.Repeat("|:-:", 9) + "|\n")
+
+	i := coord.All()
+	for i.Next() {
+		c := i.Value().(coord.Coord)
+		if c.X == 0 {
+			fmt.Fprintf(sb, "| **r%d** ", c.Y+1)
+		}
+		switch v := b.At(c).Value; {
+		case v == 0:
+			sb.WriteString("|   ")
+		case b.given[coord.Ctoi(c)]:
+			fmt.Fprintf(sb, "| **%d** ", v)
+		default:
+			fmt.Fprintf(sb, "| %d ", v)
+		}
+		if c.X == 8 {
+			sb.WriteString("|\n")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package board
+
+import "github.com/phaul/sudoku/coord"
+
+// most branches of the search for retractions, a grid tangled beyond it gets the best retractions found so far
+const retractBudget = 1 << 14
+
+// search state of Retractions
+type retraction struct {
+	conflicts [9 * 9]coord.Set // cells repeating the value of a cell in one of its houses
+	given     coord.Set
+	best      coord.Set
+	cost      int // cost of best
+	budget    int // branches left
+}
+
+// the fewest placements to erase so that no value is repeated in a house, in index order, nil if there is none
+//
+// givens are only suggested where givens repeat a value among themselves. The cells repeating values form a
+// conflict graph and the suggestion is a minimum vertex cover of it, found by branching on the cell with the most
+// conflicts.
+func (b *Board) Retractions() []coord.Coord {
+	r := retraction{cost: 1 << 30, budget: retractBudget}
+	live := coord.Set{}
+	for ix, v := range b.values {
+		if v == 0 {
+			continue
+		}
+		if b.given[ix] {
+			r.given.Add(ix)
+		}
+		for _, p := range b.layout.PeerIndices(ix) {
+			if b.values[p] == v {
+				r.conflicts[ix].Add(p)
+				live.Add(ix)
+			}
+		}
+	}
+
+	r.cover(live, coord.Set{}, 0)
+	cs := []coord.Coord{}
+	for s := r.best; !s.IsEmpty(); {
+		ix := s.First()
+		cs = append(cs, coord.Itoc(ix))
+		s.Remove(ix)
+	}
+	if len(cs) == 0 {
+		return nil
+	}
+	return cs
+}
+
+// cost of erasing the cells of s, a given costs more than all placements together
+func (r *retraction) weight(s coord.Set) int {
+	return s.Count() + 100*s.And(r.given).Count()
+}
+
+// covers the conflicts among the live cells, on top of the cells taken so far at cost
+func (r *retraction) cover(live, taken coord.Set, cost int) {
+	if cost >= r.cost || r.budget == 0 {
+		return
+	}
+	r.budget--
+
+	v, deg := -1, 0
+	for s := live; !s.IsEmpty(); {
+		ix := s.First()
+		s.Remove(ix)
+		if n := r.conflicts[ix].And(live).Count(); n > deg {
+			v, deg = ix, n
+		}
+	}
+	if v < 0 {
+		r.best, r.cost = taken, cost
+		return
+	}
+
+	one := coord.Set{}
+	one.Add(v)
+	ns := r.conflicts[v].And(live)
+	branches := [2]coord.Set{one, ns}
+	if r.given.Has(v) {
+		// keeping a given is likely cheaper
+		branches[0], branches[1] = ns, one
+	}
+	for _, s := range branches {
+		rest := live.AndNot(s)
+		rest.Remove(v)
+		r.cover(rest, taken.Or(s), cost+r.weight(s))
+	}
+}
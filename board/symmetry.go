@@ -0,0 +1,102 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// symmetries of a clue pattern, as a set of flags
+type Symmetry int
+
+const (
+	Rotational   Symmetry = 1 << iota // the same after a half turn around the center
+	QuarterTurn                       // the same after a quarter turn around the center
+	LeftRight                         // mirrored over the middle column
+	TopBottom                         // mirrored over the middle row
+	Diagonal                          // mirrored over the diagonal from the top left corner
+	AntiDiagonal                      // mirrored over the diagonal from the top right corner
+	NoSymmetry   Symmetry = 0
+)
+
+// every symmetry, in the order of the flags
+var AllSymmetries = []Symmetry{Rotational, QuarterTurn, LeftRight, TopBottom, Diagonal, AntiDiagonal}
+
+// the cell x, y moves to under a single symmetry s
+func (s Symmetry) image(x, y int) (int, int) {
+	switch s {
+	case Rotational:
+		return 8 - x, 8 - y
+	case QuarterTurn:
+		return 8 - y, x
+	case LeftRight:
+		return 8 - x, y
+	case TopBottom:
+		return x, 8 - y
+	case Diagonal:
+		return y, x
+	case AntiDiagonal:
+		return 8 - y, 8 - x
+	}
+	return x, y
+}
+
+func (s Symmetry) String() string {
+	if s == NoSymmetry {
+		return "none"
+	}
+	names := []string{}
+	for _, f := range AllSymmetries {
+		if s&f != 0 {
+			names = append(names, f.name())
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// name of a single symmetry
+func (s Symmetry) name() string {
+	switch s {
+	case Rotational:
+		return "rotational"
+	case QuarterTurn:
+		return "quarter-turn"
+	case LeftRight:
+		return "left-right"
+	case TopBottom:
+		return "top-bottom"
+	case Diagonal:
+		return "diagonal"
+	case AntiDiagonal:
+		return "anti-diagonal"
+	}
+	return fmt.Sprintf("Symmetry(%d)", int(s))
+}
+
+// the single symmetry named s, as in String
+func ParseSymmetry(s string) (Symmetry, error) {
+	for _, f := range AllSymmetries {
+		if f.name() == s {
+			return f, nil
+		}
+	}
+	return NoSymmetry, fmt.Errorf("unknown symmetry %q", s)
+}
+
+// the symmetries of the clue pattern of b: the transformations taking every filled cell to a filled cell
+//
+// only the positions of the clues matter, not their values. An empty board has every symmetry.
+func (b *Board) Symmetry() Symmetry {
+	r := NoSymmetry
+
+	for _, s := range AllSymmetries {
+		ok := true
+		for ix := 0; ix < len(b.values) && ok; ix++ {
+			x, y := s.image(ix%9, ix/9)
+			ok = (b.values[ix] == 0) == (b.values[y*9+x] == 0)
+		}
+		if ok {
+			r |= s
+		}
+	}
+	return r
+}
@@ -0,0 +1,43 @@
+package board
+
+import (
+	"fmt"
+
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// the board in the 81 character line format, as Line
+func (b Board) MarshalText() ([]byte, error) { return []byte(b.Line()), nil }
+
+// restores b from the 81 character line format, digits are givens and '0' or '.' are empty cells
+//
+// the layout of b is kept, a zero board takes coord.Standard. Givens repeating a value in a house are an
+// *InvalidPuzzleError.
+func (b *Board) UnmarshalText(text []byte) error {
+	if len(text) != 9*9 {
+		return fmt.Errorf("puzzle has %d characters instead of 81", len(text))
+	}
+
+	v := [9 * 9]cell.ValT{}
+	for ix, ch := range text {
+		switch {
+		case ch == '.' || ch == '0':
+		case '1' <= ch && ch <= '9':
+			v[ix] = cell.ValT(ch - '0')
+		default:
+			return fmt.Errorf("invalid character %q at %d", ch, ix+1)
+		}
+	}
+
+	l := b.layout
+	if l.IsZero() {
+		l = coord.Standard
+	}
+	r := FromValues(l, v)
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	*b = r
+	return nil
+}
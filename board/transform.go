@@ -0,0 +1,52 @@
+package board
+
+import (
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// a validity preserving transformation of a board: quarter turns, then a mirror, then relabeling the digits
+//
+// turns and mirrors take every house of the built in layouts onto a house of the same layout, so a transformed puzzle
+// has the transformed solutions of the original.
+type Transform struct {
+	Turns   int          // quarter turns clockwise, negative for counterclockwise
+	Mirror  Symmetry     // a single symmetry mirroring the turned board, NoSymmetry for none
+	Relabel [9]cell.ValT // the digit replacing each of 1 to 9, a permutation, or all zero to keep the digits
+}
+
+// the cell x, y moves to under t
+func (t Transform) image(x, y int) (int, int) {
+	for range (t.Turns%4 + 4) % 4 {
+		x, y = QuarterTurn.image(x, y)
+	}
+	return t.Mirror.image(x, y)
+}
+
+// the digit v is replaced by under t
+func (t Transform) label(v cell.ValT) cell.ValT {
+	if v == 0 || t.Relabel[v-1] == 0 {
+		return v
+	}
+	return t.Relabel[v-1]
+}
+
+// b transformed by t
+//
+// givens stay givens and other values stay placed values, the candidates are recomputed from the values.
+func (b *Board) Transform(t Transform) Board {
+	r := New(b.layout)
+	for ix, v := range b.values {
+		if v == 0 {
+			continue
+		}
+		x, y := t.image(ix%9, ix/9)
+		c := coord.Itoc(y*9 + x)
+		if b.given[ix] {
+			r.Give(c, t.label(v))
+		} else {
+			r.Fill(c, t.label(v))
+		}
+	}
+	return r
+}
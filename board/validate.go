@@ -0,0 +1,42 @@
+package board
+
+import "github.com/phaul/sudoku/coord"
+
+// an *InvalidPuzzleError if a value is repeated in a house or in linked cells, an error wrapping ErrCageSum if the
+// values of a killer cage go over its sum or fill it without adding up to it
+func (b *Board) Validate() error {
+	if c, ok := b.Duplicate(); ok {
+		return &InvalidPuzzleError{Coord: c, Value: b.At(c).Value}
+	}
+	if k := b.brokenCage(); k >= 0 {
+		return b.cageError(k)
+	}
+	return nil
+}
+
+// a cell holding the same value as another cell in one of its houses or linked with it, if there is one
+func (b *Board) Duplicate() (coord.Coord, bool) {
+	i := b.layout.Houses()
+
+	for i.Next() {
+		r := i.Value().(coord.Iterator)
+		seen := [10]bool{}
+
+		for r.Next() {
+			c := r.Value().(coord.Coord)
+			v := b.At(c).Value
+			if v != 0 && seen[v] {
+				return c, true
+			}
+			seen[v] = true
+		}
+	}
+	for ix, v := range b.values {
+		for _, p := range b.layout.Links(ix) {
+			if v != 0 && b.values[p] == v && p > ix {
+				return coord.Itoc(p), true
+			}
+		}
+	}
+	return coord.Coord{}, false
+}
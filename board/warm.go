@@ -0,0 +1,41 @@
+package board
+
+import (
+	"math/bits"
+
+	"github.com/phaul/sudoku/cell"
+)
+
+// how the candidates of a board with progress on it are treated before solving
+type Marks int
+
+const (
+	RecomputeMarks Marks = iota // derive the candidates from the placed values, dropping the pencil marks
+	TrustMarks                  // keep the pencil marks, only dropping the candidates the placed values rule out
+)
+
+// reconciles the candidates of b, that can hold user placements and pencil marks, with its placed values
+//
+// values repeated in a house are an *InvalidPuzzleError. Trusted pencil marks can make the puzzle unsolvable if a
+// candidate of the solution was eliminated by mistake, but never lead to a wrong solution.
+func (b *Board) Warm(m Marks) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if m == RecomputeMarks {
+		b.RecomputeCandidates()
+		return nil
+	}
+
+	b.recomputePlaced()
+	for ix, v := range b.values {
+		ms := b.masks[ix]
+		if v == 0 {
+			ms &= b.ruled(ix)
+		}
+		for ; ms != 0; ms &= ms - 1 {
+			b.Drop(ix, cell.ValT(bits.TrailingZeros(uint(ms))+1))
+		}
+	}
+	return nil
+}
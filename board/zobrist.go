@@ -0,0 +1,29 @@
+package board
+
+// random keys of the digits placed in the cells, the hash of a board is the xor of the keys of its values
+//
+// the keys come from a fixed splitmix64 sequence, so hashes are stable between runs and can be stored
+var zobrist = func() (z [9 * 9][9]uint64) {
+	x := uint64(0x5eed5d0c)
+	for ix := range z {
+		for d := range z[ix] {
+			x += 0x9e3779b97f4a7c15
+			k := x
+			k = (k ^ k>>30) * 0xbf58476d1ce4e5b9
+			k = (k ^ k>>27) * 0x94d049bb133111eb
+			z[ix][d] = k ^ k>>31
+		}
+	}
+	return
+}()
+
+// hash of the values of b from scratch, as Fill maintains it incrementally
+func (b *Board) rehash() uint64 {
+	h := uint64(0)
+	for ix, v := range b.values {
+		if v != 0 {
+			h ^= zobrist[ix][v-1]
+		}
+	}
+	return h
+}
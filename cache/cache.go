@@ -0,0 +1,94 @@
+// least recently used cache, safe for concurrent use
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// an LRU cache holding at most size entries, each for at most ttl
+type Cache[K comparable, V any] struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[K]*list.Element
+	order *list.List // most recently used first
+	now   func() time.Time
+}
+
+type item[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// a cache of size entries, evicting the least recently used on overflow; ttl 0 keeps entries until evicted
+func New[K comparable, V any](size int, ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{size: max(size, 1), ttl: ttl, items: map[K]*list.Element{}, order: list.New(), now: time.Now}
+}
+
+// the value stored for k, false if there is none or it expired
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[k]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	it := e.Value.(*item[K, V])
+	if c.ttl > 0 && c.now().After(it.expires) {
+		c.remove(e)
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return it.value, true
+}
+
+// stores v for k, evicting the least recently used entry if the cache is full
+func (c *Cache[K, V]) Put(k K, v V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	exp := time.Time{}
+	if c.ttl > 0 {
+		exp = c.now().Add(c.ttl)
+	}
+	if e, ok := c.items[k]; ok {
+		it := e.Value.(*item[K, V])
+		it.value, it.expires = v, exp
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[k] = c.order.PushFront(&item[K, V]{key: k, value: v, expires: exp})
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// drops the entry of k
+func (c *Cache[K, V]) Remove(k K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[k]; ok {
+		c.remove(e)
+	}
+}
+
+// number of entries, including the expired ones not yet dropped
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache[K, V]) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.items, e.Value.(*item[K, V]).key)
+}
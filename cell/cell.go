@@ -2,33 +2,44 @@ package cell
 
 import "math/bits"
 
-type ValT uint8  // value of a cell, 0 empty, 1-9 otherwise
-type canT uint16 // bitmap of what cell can be 0-8 bits used to indicate a cell can take ix+1 as value
+type ValT uint8 // value of a cell, 0 empty, 1-MaxValue otherwise
 
-// everything is possible
-const everything = canT(0x1ff)
+// the 9 digits are possible
+const everything = MaskT(0x1ff)
+
+// bitmap with everything possible, as returned by Mask
+const Everything = uint16(everything)
 
 // nothing is possible
-const none = canT(0)
+const none = MaskT(0)
 
 // empty cell
 const empty = ValT(0)
 
-// a pair of values, holding a digit 1-9 or 0 indicating unsolved cell
-// and a bitmask that is set '1' for each possible digit for the cell
+// a pair of values, holding a value 1-MaxValue or 0 indicating unsolved cell
+// and a bitmask that is set '1' for each possible value for the cell
 type Cell struct {
-	Value ValT // value of the cell
-	can   canT // possibilities for the cell
+	Value ValT  // value of the cell
+	can   MaskT // possibilities for the cell
 }
 
 type possibilityIterator struct {
-	can  canT
+	can  MaskT
 	init bool
 }
 
 // a cell with Value v and Possibilites 0
 func New(v ValT) Cell { return Cell{Value: v} }
 
+// a cell with Value v and the possibilities in bitmap m, as returned by Mask
+func Of(v ValT, m uint16) Cell { return Cell{Value: v, can: MaskT(m)} }
+
+// a cell with Value v and the possibilities in bitmap m, as returned by Bits
+func OfBits(v ValT, m MaskT) Cell { return Cell{Value: v, can: m} }
+
+// bitmap of the values 1 to n, n at most MaxValue
+func Domain(n int) MaskT { return MaskT(1)<<n - 1 }
+
 // is the cell empty? (Val: 0)
 func (c Cell) IsEmpty() bool { return c.Value == empty }
 
@@ -50,25 +61,39 @@ func (p *possibilityIterator) Next() bool {
 
 // value yielded by the iterator
 func (p possibilityIterator) Value() ValT {
-	return ValT(bits.TrailingZeros16(uint16(p.can)) + 1)
+	return ValT(bits.TrailingZeros(uint(p.can)) + 1)
 }
 
 // set all digits possible in the cell
 func (c *Cell) SetAll() { c.can = everything }
 
+// set the values 1 to n possible in the cell, n at most MaxValue
+func (c *Cell) SetDomain(n int) { c.can = Domain(n) }
+
 // drops v as a possibility
 func (c *Cell) Drop(v ValT) { c.can &= (^(1 << (v - 1))) }
 
+// adds v as a possibility if it wasn't one, drops it otherwise
+func (c *Cell) Toggle(v ValT) { c.can ^= 1 << (v - 1) }
+
 // does the cell hold a single possibility?
 func (c Cell) IsSingle() bool {
 	return c.can != none && c.can&(c.can-1) == none
 }
 
 // The first possible value for the cell
-func (c Cell) FirstPossibility() ValT { return ValT(bits.TrailingZeros16(uint16(c.can)) + 1) }
+func (c Cell) FirstPossibility() ValT { return ValT(bits.TrailingZeros(uint(c.can)) + 1) }
 
 // Is v possible in the cell c
 func (c Cell) IsPossible(v ValT) bool { return c.can&(1<<(v-1)) != none }
 
+// bitmap of the possibilities, bit v-1 is set if v is possible
+//
+// only the values up to 16 fit, Bits has all of them
+func (c Cell) Mask() uint16 { return uint16(c.can) }
+
+// bitmap of the possibilities, bit v-1 is set if v is possible
+func (c Cell) Bits() MaskT { return c.can }
+
 // count the possible digits for the cell
-func (c Cell) PossibilityCount() int { return bits.OnesCount16(uint16(c.can)) }
+func (c Cell) PossibilityCount() int { return bits.OnesCount(uint(c.can)) }
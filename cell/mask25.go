@@ -0,0 +1,9 @@
+//go:build wide
+
+package cell
+
+// the largest value a cell can hold, build without the wide tag for the faster 9 value cells
+const MaxValue = 25
+
+// bitmap of the values a cell can take, bit v-1 for the value v
+type MaskT uint32
@@ -0,0 +1,9 @@
+//go:build !wide
+
+package cell
+
+// the largest value a cell can hold, build with the wide tag for up to 25
+const MaxValue = 9
+
+// bitmap of the values a cell can take, bit v-1 for the value v
+type MaskT uint16
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+)
+
+// values of -color
+var colorModes = []string{"auto", "always", "never"}
+
+// is f a terminal?
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// the theme of the boards printed to f: the theme called name, or dark with colors and plain without for an empty name
+//
+// color mode auto has colors if f is a terminal and the NO_COLOR environment variable is empty, never drops the colors
+// of the theme, highlights included.
+func pickTheme(name, mode string, f *os.File) (*board.Theme, error) {
+	color := false
+	switch mode {
+	case "auto":
+		color = isTerminal(f) && os.Getenv("NO_COLOR") == ""
+	case "always":
+		color = true
+	case "never":
+	default:
+		return nil, fmt.Errorf("unknown color mode %q, expected one of %s", mode, strings.Join(colorModes, ", "))
+	}
+
+	t := &board.Plain
+	switch {
+	case name != "":
+		var ok bool
+		if t, ok = board.Themes[name]; !ok {
+			return nil, fmt.Errorf("unknown theme %q", name)
+		}
+	case color:
+		t = &board.Dark
+	}
+	if !color {
+		return &board.Theme{Empty: t.Empty}, nil
+	}
+	return t, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/solve"
+)
+
+// shells with completion support
+var shells = []string{"bash", "zsh", "fish"}
+
+// values of the flags that take one out of a fixed set, for completion
+func flagValues() map[string][]string {
+	return map[string][]string{
+		"variant":    slices.Sorted(maps.Keys(variants)),
+		"solver":     append([]string{"auto"}, slices.Sorted(maps.Keys(solve.Solvers))...),
+		"completion": shells,
+		"symmetry":   symmetryNames(),
+		"mirror":     mirrorNames(),
+		"enable":     techniqueNames(),
+		"disable":    techniqueNames(),
+		"theme":      slices.Sorted(maps.Keys(board.Themes)),
+		"difficulty": difficultyNames(),
+		"notation":   slices.Sorted(maps.Keys(notations)),
+		"output":     outputs,
+		"o":          outputs,
+		"color":      colorModes,
+	}
+}
+
+// flags that don't take an argument
+func isBool(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// writes the completion script of shell for the command line flags of the program called name
+func completion(w io.Writer, shell, name string) error {
+	switch shell {
+	case "bash":
+		bashCompletion(w, name)
+	case "zsh":
+		zshCompletion(w, name)
+	case "fish":
+		fishCompletion(w, name)
+	default:
+		return fmt.Errorf("unknown shell %q, expected one of %s", shell, strings.Join(shells, ", "))
+	}
+	return nil
+}
+
+func bashCompletion(w io.Writer, name string) {
+	fn := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	flags := []string{}
+	flag.VisitAll(func(f *flag.Flag) { flags = append(flags, "-"+f.Name) })
+
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "\tcase \"$prev\" in\n")
+	vs := flagValues()
+	for _, f := range slices.Sorted(maps.Keys(vs)) {
+		fmt.Fprintf(w, "\t-%s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", f, strings.Join(vs[f], " "))
+	}
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "\tif [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(flags, " "))
+	fmt.Fprintf(w, "\telif [[ $COMP_CWORD == 1 ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W \"%s\" -- \"$cur\") $(compgen -f -- \"$cur\"))\n",
+		strings.Join(subcommandNames(), " "))
+	fmt.Fprintf(w, "\telse\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\tfi\n}\n")
+	fmt.Fprintf(w, "complete -F %s %s\n", fn, name)
+}
+
+func zshCompletion(w io.Writer, name string) {
+	esc := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`)
+	vs := flagValues()
+
+	fmt.Fprintf(w, "#compdef %s\n\n_arguments \\\n", name)
+	flag.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(w, "\t'-%s[%s]", f.Name, esc.Replace(f.Usage))
+		switch {
+		case vs[f.Name] != nil:
+			fmt.Fprintf(w, ":%s:(%s)", f.Name, strings.Join(vs[f.Name], " "))
+		case !isBool(f):
+			fmt.Fprintf(w, ":%s:", f.Name)
+		}
+		fmt.Fprintf(w, "' \\\n")
+	})
+	fmt.Fprintf(w, "\t'1:command or file:_alternative \"commands:command:(%s)\" \"files:file:_files\"' \\\n",
+		strings.Join(subcommandNames(), " "))
+	fmt.Fprintf(w, "\t'*:file:_files'\n")
+}
+
+func fishCompletion(w io.Writer, name string) {
+	esc := strings.NewReplacer(`\`, `\\`, "'", `\'`)
+	vs := flagValues()
+
+	flag.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(w, "complete -c %s -o %s", name, f.Name)
+		switch {
+		case vs[f.Name] != nil:
+			fmt.Fprintf(w, " -x -a '%s'", strings.Join(vs[f.Name], " "))
+		case !isBool(f):
+			fmt.Fprintf(w, " -r")
+		}
+		fmt.Fprintf(w, " -d '%s'\n", esc.Replace(f.Usage))
+	})
+	fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a '%s'\n", name, strings.Join(subcommandNames(), " "))
+}
@@ -36,14 +36,37 @@
 //	}
 package coord
 
+import (
+	"errors"
+	"fmt"
+)
+
 type dim int8
 type Coord struct {
 	X, Y dim // X,Y coordinates on a sudoku board
 }
 
 // coordinate to integer
+//
+// c has to be on the board, see CtoiChecked for coordinates from outside of the program
 func Ctoi(c Coord) int {
-	return int(c.Y*9 + c.X)
+	return int(c.Y)*9 + int(c.X)
+}
+
+// coordinates outside of the 9x9 board
+var ErrOutOfRange = errors.New("coordinate out of range")
+
+// coordinate to integer, an error wrapping ErrOutOfRange if c is not on the board
+func CtoiChecked(c Coord) (int, error) {
+	if c.X < 0 || c.X >= 9 || c.Y < 0 || c.Y >= 9 {
+		return 0, fmt.Errorf("%w: x %d, y %d", ErrOutOfRange, c.X, c.Y)
+	}
+	return Ctoi(c), nil
+}
+
+// integer to coordinate, the inverse of Ctoi
+func Itoc(i int) Coord {
+	return Coord{dim(i % 9), dim(i / 9)}
 }
 
 // composed iterator iterating first a then b
@@ -74,6 +97,11 @@ func Box(c Coord) *boxIterator {
 	return &i
 }
 
+// coordinates for the cells at the same position within each 3x3 box as c
+func DisjointGroup(c Coord) *disjointGroupIterator {
+	return &disjointGroupIterator{base: Coord{c.X % 3, c.Y % 3}, i: -1}
+}
+
 // iterator that yields row iterators, one for each column
 func AllRows() *allRowsIterator { return &allRowsIterator{i: -1} }
 
@@ -83,7 +111,8 @@ func AllColumns() *allColumnsIterator { return &allColumnsIterator{i: -1} }
 // iterator that yields box iterators, one for each 3x3 box of sudoku
 func AllBoxes() *allBoxesIterator { return &allBoxesIterator{i: -1} }
 
-type any interface{}
+// iterator that yields disjoint group iterators, one for each position within a 3x3 box
+func AllDisjointGroups() *allDisjointGroupsIterator { return &allDisjointGroupsIterator{i: -1} }
 
 // iterator
 type Iterator interface {
@@ -195,6 +224,24 @@ func (i *boxIterator) Reset() {
 	i.i = -1
 }
 
+type disjointGroupIterator struct {
+	base Coord
+	i    dim
+}
+
+func (i *disjointGroupIterator) Next() bool {
+	i.i++
+	return i.i < 9
+}
+
+func (i disjointGroupIterator) Value() any {
+	return Coord{i.base.X + i.i%3*3, i.base.Y + i.i/3*3}
+}
+
+func (i *disjointGroupIterator) Reset() {
+	i.i = -1
+}
+
 type allRowsIterator struct {
 	i dim
 }
@@ -244,3 +291,18 @@ func (i allBoxesIterator) Value() any {
 func (i *allBoxesIterator) Reset() {
 	i.i = -1
 }
+
+type allDisjointGroupsIterator struct{ i dim }
+
+func (i *allDisjointGroupsIterator) Next() bool {
+	i.i++
+	return i.i < 9
+}
+
+func (i allDisjointGroupsIterator) Value() any {
+	return DisjointGroup(Coord{i.i % 3, i.i / 3})
+}
+
+func (i *allDisjointGroupsIterator) Reset() {
+	i.i = -1
+}
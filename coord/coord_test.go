@@ -0,0 +1,116 @@
+package coord_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/coord/coordtest"
+)
+
+// a jigsaw of the boxes with r3c3 and r3c4 swapped between the first two
+func jigsaw(t *testing.T) coord.Layout {
+	t.Helper()
+	pieces := [9][9]coord.Coord{}
+	for ix := range 9 * 9 {
+		c := coord.Itoc(ix)
+		b, n := ix/27*3+ix%9/3, ix/9%3*3+ix%3
+		switch ix {
+		case 2*9 + 2:
+			c = coord.Itoc(2*9 + 3)
+		case 2*9 + 3:
+			c = coord.Itoc(2*9 + 2)
+		}
+		pieces[b][n] = c
+	}
+	l, err := coord.LatinWith(pieces[:]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// a killer with a few cages of up to 5 cells
+func killer(t *testing.T) coord.Layout {
+	t.Helper()
+	l, err := coord.Killer(
+		coord.Cage{Cells: []coord.Coord{coord.Itoc(0), coord.Itoc(1)}, Sum: 3},
+		coord.Cage{Cells: []coord.Coord{coord.Itoc(2), coord.Itoc(11), coord.Itoc(20)}, Sum: 24},
+		coord.Cage{Cells: []coord.Coord{coord.Itoc(40)}, Sum: 5},
+		coord.Cage{Cells: []coord.Coord{coord.Itoc(8), coord.Itoc(17), coord.Itoc(16), coord.Itoc(15), coord.Itoc(24)},
+			Sum: 25},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// an iterator under test and what it yields
+type iteratorCase struct {
+	f func() coord.Iterator
+	s coordtest.Spec
+}
+
+func TestIterators(t *testing.T) {
+	houses := coordtest.Spec{Len: 9, Cover: true}
+	diagonals := coordtest.Spec{Len: 2, Duplicates: true} // the center is on both
+	for name, c := range map[string]iteratorCase{
+		"All":               {func() coord.Iterator { return coord.All() }, coordtest.Spec{Len: 9 * 9, Cover: true}},
+		"AllRows":           {func() coord.Iterator { return coord.AllRows() }, houses},
+		"AllColumns":        {func() coord.Iterator { return coord.AllColumns() }, houses},
+		"AllBoxes":          {func() coord.Iterator { return coord.AllBoxes() }, houses},
+		"AllDisjointGroups": {func() coord.Iterator { return coord.AllDisjointGroups() }, houses},
+		"Diagonal":          {func() coord.Iterator { return coord.Diagonal() }, coordtest.Spec{Len: 9}},
+		"AntiDiagonal":      {func() coord.Iterator { return coord.AntiDiagonal() }, coordtest.Spec{Len: 9}},
+		"AllDiagonals":      {func() coord.Iterator { return coord.AllDiagonals() }, diagonals},
+		"AllWindows":        {func() coord.Iterator { return coord.AllWindows() }, coordtest.Spec{Len: 4}},
+	} {
+		t.Run(name, func(t *testing.T) { coordtest.Run(t, c.f, c.s) })
+	}
+}
+
+func TestIteratorsOfCells(t *testing.T) {
+	for ix := range 9 * 9 {
+		c := coord.Itoc(ix)
+		window := coordtest.Spec{}
+		if c.X%4 != 0 && c.Y%4 != 0 {
+			window.Len = 9
+		}
+		for name, it := range map[string]iteratorCase{
+			"Row":           {func() coord.Iterator { return coord.Row(c) }, coordtest.Spec{Len: 9}},
+			"Column":        {func() coord.Iterator { return coord.Column(c) }, coordtest.Spec{Len: 9}},
+			"Box":           {func() coord.Iterator { return coord.Box(c) }, coordtest.Spec{Len: 9}},
+			"DisjointGroup": {func() coord.Iterator { return coord.DisjointGroup(c) }, coordtest.Spec{Len: 9}},
+			"Window":        {func() coord.Iterator { return coord.Window(c) }, window},
+			"Composed": {
+				func() coord.Iterator { return coord.Composed(coord.Row(c), coord.Column(c)) },
+				coordtest.Spec{Len: 2 * 9, Duplicates: true},
+			},
+		} {
+			t.Run(fmt.Sprintf("%s/r%dc%d", name, c.Y+1, c.X+1), func(t *testing.T) { coordtest.Run(t, it.f, it.s) })
+		}
+	}
+}
+
+func TestLayouts(t *testing.T) {
+	for name, l := range map[string]coord.Layout{
+		"standard":    coord.Standard,
+		"latin":       coord.Latin,
+		"disjoint":    coord.DisjointGroups,
+		"x":           coord.X,
+		"windoku":     coord.Windoku,
+		"anti-knight": coord.AntiKnight,
+		"jigsaw":      jigsaw(t),
+		"killer":      killer(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			coordtest.RunLayout(t, l)
+			for ix := range 9 * 9 {
+				n := 9*len(l.HousesOf(ix)) + len(l.Links(ix))
+				coordtest.Run(t, func() coord.Iterator { return l.Peers(coord.Itoc(ix)) },
+					coordtest.Spec{Len: n, Duplicates: true})
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Conformance checks for coordinate iterators and layouts, for the tests of new iterators and variants
+//
+// Example:
+//
+//	func TestJigsaw(t *testing.T) {
+//		coordtest.Run(t, func() coord.Iterator { return jigsawPieces() }, coordtest.Spec{Len: 9, Cover: true})
+//		coordtest.RunLayout(t, jigsaw)
+//	}
+package coordtest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/phaul/sudoku/coord"
+)
+
+// what an iterator under test yields
+type Spec struct {
+	Len        int  // number of values
+	Duplicates bool // cells may repeat, as in the peers of a layout
+	Cover      bool // the cells, with the cells of nested iterators, are all 81 cells of the board
+}
+
+// an iterator yielding more values than this is taken as never ending
+const runaway = 10 * 9 * 9
+
+// checks the iterators f returns against s and the contract of coord.Iterator
+//
+// values have to be coordinates on the board, or iterators of 9 distinct coordinates like the rows of AllRows. Two
+// iterators of f yield the same sequence, an exhausted iterator stays exhausted and Reset, whether the iterator was
+// exhausted or not, starts the same sequence again. Nested iterators are held to the same.
+func Run(t *testing.T, f func() coord.Iterator, s Spec) {
+	t.Helper()
+
+	i := f()
+	vs, err := drain(i)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(vs) != s.Len {
+		t.Errorf("iterator yields %d values instead of %d", len(vs), s.Len)
+	}
+	if i.Next() {
+		t.Error("exhausted iterator yields another value")
+	}
+
+	cells := [9 * 9]int{}
+	for _, v := range vs {
+		for _, ix := range v {
+			cells[ix]++
+		}
+	}
+	for ix, n := range cells {
+		c := coord.Itoc(ix)
+		switch {
+		case n > 1 && !s.Duplicates:
+			t.Errorf("cell r%dc%d yielded %d times", c.Y+1, c.X+1, n)
+		case n == 0 && s.Cover:
+			t.Errorf("cell r%dc%d not covered", c.Y+1, c.X+1)
+		}
+	}
+
+	if again, err := drain(f()); err != nil || !equal(vs, again) {
+		t.Error("a second iterator yields a different sequence")
+	}
+	i.Reset()
+	if again, err := drain(i); err != nil || !equal(vs, again) {
+		t.Error("Reset after exhausting the iterator doesn't restart the sequence")
+	}
+	for n := range len(vs) {
+		i.Reset()
+		for range n {
+			i.Next()
+		}
+		i.Reset()
+		if again, err := drain(i); err != nil || !equal(vs, again) {
+			t.Errorf("Reset after %d values doesn't restart the sequence", n)
+			break
+		}
+	}
+}
+
+// the values of i until it's exhausted, each as the cell indices of a coordinate or a nested iterator
+func drain(i coord.Iterator) ([][]int, error) {
+	vs := [][]int{}
+	for i.Next() {
+		if len(vs) == runaway {
+			return nil, fmt.Errorf("iterator yields more than %d values", runaway)
+		}
+		switch v := i.Value().(type) {
+		case coord.Coord:
+			ix, err := coord.CtoiChecked(v)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, []int{ix})
+		case coord.Iterator:
+			h, err := house(v)
+			if err != nil {
+				return nil, fmt.Errorf("value %d: %w", len(vs)+1, err)
+			}
+			vs = append(vs, h)
+		default:
+			return nil, fmt.Errorf("value %d is a %T, not a coord.Coord or a coord.Iterator", len(vs)+1, v)
+		}
+	}
+	return vs, nil
+}
+
+// the cell indices of the house i iterates, checked to be 9 distinct cells that Reset iterates again
+func house(i coord.Iterator) ([]int, error) {
+	h := []int{}
+	seen := [9 * 9]bool{}
+	for pass := range 2 {
+		h = h[:0]
+		seen = [9 * 9]bool{}
+		for i.Next() {
+			c, ok := i.Value().(coord.Coord)
+			if !ok {
+				return nil, fmt.Errorf("house yields a %T, not a coord.Coord", i.Value())
+			}
+			ix, err := coord.CtoiChecked(c)
+			if err != nil {
+				return nil, err
+			}
+			if seen[ix] {
+				return nil, fmt.Errorf("house repeats r%dc%d", c.Y+1, c.X+1)
+			}
+			if len(h) == 9 {
+				return nil, fmt.Errorf("house has more than 9 cells")
+			}
+			seen[ix] = true
+			h = append(h, ix)
+		}
+		if len(h) != 9 {
+			return nil, fmt.Errorf("house has %d cells instead of 9", len(h))
+		}
+		if pass == 0 {
+			i.Reset()
+		}
+	}
+	return h, nil
+}
+
+// a and b hold the same values in the same order
+func equal(a, b [][]int) bool {
+	return slices.EqualFunc(a, b, func(x, y []int) bool { return slices.Equal(x, y) })
+}
+
+// checks the houses of l and the lookup tables derived from them
+//
+// Houses yields at most coord.MaxHouses houses of 9 distinct cells, in the order of HouseIndices, covering every
+// cell. HousesOf and HouseSet agree with the houses. Links are symmetric, outside of the houses of the cell, and
+// PeerIndices, PeerSet and Peers agree with the houses and the links.
+func RunLayout(t *testing.T, l coord.Layout) {
+	t.Helper()
+
+	hs := l.HouseIndices()
+	Run(t, l.Houses, Spec{Len: len(hs), Duplicates: true, Cover: true})
+	if len(hs) > coord.MaxHouses {
+		t.Errorf("%d houses, more than %d", len(hs), coord.MaxHouses)
+	}
+
+	i := l.Houses()
+	for n := 0; i.Next() && n < len(hs); n++ {
+		h, err := house(i.Value().(coord.Iterator))
+		if err != nil {
+			return // reported by Run
+		}
+		if !slices.Equal(h, hs[n][:]) {
+			t.Errorf("house %d is %v in Houses and %v in HouseIndices", n+1, h, hs[n])
+		}
+		s := coord.Set{}
+		for _, ix := range h {
+			s.Add(ix)
+		}
+		if l.HouseSet(n) != s {
+			t.Errorf("HouseSet of house %d doesn't match its cells", n+1)
+		}
+	}
+
+	for ix := range 9 * 9 {
+		c := coord.Itoc(ix)
+		of := []int{}
+		peers := coord.Set{}
+		for n, h := range hs {
+			if slices.Contains(h[:], ix) {
+				of = append(of, n)
+				for _, p := range h {
+					peers.Add(p)
+				}
+			}
+		}
+		peers.Remove(ix)
+		for _, p := range l.Links(ix) {
+			switch {
+			case peers.Has(p):
+				t.Errorf("r%dc%d is linked with a cell of its houses, %d", c.Y+1, c.X+1, p)
+			case !slices.Contains(l.Links(p), ix):
+				t.Errorf("r%dc%d is linked with %d, but not the other way round", c.Y+1, c.X+1, p)
+			}
+			peers.Add(p)
+		}
+
+		if !slices.Equal(l.HousesOf(ix), of) {
+			t.Errorf("HousesOf r%dc%d is %v instead of %v", c.Y+1, c.X+1, l.HousesOf(ix), of)
+		}
+		if l.PeerSet(ix) != peers {
+			t.Errorf("PeerSet of r%dc%d doesn't match its houses and links", c.Y+1, c.X+1)
+		}
+		ps := coord.Set{}
+		for _, p := range l.PeerIndices(ix) {
+			if ps.Has(p) {
+				t.Errorf("PeerIndices of r%dc%d repeats %d", c.Y+1, c.X+1, p)
+			}
+			ps.Add(p)
+		}
+		if ps != peers {
+			t.Errorf("PeerIndices of r%dc%d don't match its houses and links", c.Y+1, c.X+1)
+		}
+
+		peers.Add(ix)
+		yielded := coord.Set{}
+		pi := l.Peers(c)
+		for pi.Next() {
+			yielded.Add(coord.Ctoi(pi.Value().(coord.Coord)))
+		}
+		if yielded != peers {
+			t.Errorf("Peers of r%dc%d doesn't yield the cell and its peers", c.Y+1, c.X+1)
+		}
+	}
+}
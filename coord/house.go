@@ -0,0 +1,43 @@
+package coord
+
+import (
+	"fmt"
+	"iter"
+)
+
+// a house of a layout, knowing its kind and its place among the houses of the kind
+//
+// a House is a small handle, its ID looks up the cells in HouseIndices or HouseSet. The zero House is no house, with
+// an empty Kind.
+type House struct {
+	ID     int    // index of the house in the layout, as in HouseIndices
+	Kind   string // kind of the house, like "box"
+	Number int    // number of the house within its kind, counting from 1
+}
+
+// the name of the house, like "box 5"
+func (h House) String() string {
+	if h.Kind == "" {
+		return "no house"
+	}
+	return fmt.Sprintf("%s %d", h.Kind, h.Number)
+}
+
+// house h of l, h indexing HouseIndices
+func (l Layout) House(h int) House {
+	return House{ID: h, Kind: l.kindOf[h], Number: l.numbers[h]}
+}
+
+// the houses of l, in the order of HouseIndices
+func (l Layout) AllHouses() iter.Seq[House] {
+	return func(yield func(House) bool) {
+		for h := range l.houses {
+			if !yield(l.House(h)) {
+				return
+			}
+		}
+	}
+}
+
+// the rows, columns and boxes of the standard layout
+func AllHouses() iter.Seq[House] { return Standard.AllHouses() }
@@ -0,0 +1,83 @@
+package coord
+
+import "fmt"
+
+// Latin squares: rows and columns only, without boxes
+var Latin = newLayout(rows, columns)
+
+// a Latin square layout with extra houses, each of 9 distinct cells on the board
+//
+// at most MaxHouses-18 extra houses fit next to the rows and columns
+func LatinWith(houses ...[9]Coord) (Layout, error) {
+	if len(houses) > MaxHouses-2*9 {
+		return Layout{}, fmt.Errorf("%d extra houses, at most %d fit", len(houses), MaxHouses-2*9)
+	}
+	for n, h := range houses {
+		seen := [9 * 9]bool{}
+		for _, c := range h {
+			ix, err := CtoiChecked(c)
+			if err != nil {
+				return Layout{}, fmt.Errorf("house %d: %w", n+1, err)
+			}
+			if seen[ix] {
+				return Layout{}, fmt.Errorf("house %d: cell r%dc%d repeated", n+1, c.Y+1, c.X+1)
+			}
+			seen[ix] = true
+		}
+	}
+
+	custom := houseKind{
+		name: "house",
+		of: func(c Coord) Iterator {
+			var i Iterator = &emptyIterator{}
+			for _, h := range houses {
+				for _, hc := range h {
+					if hc == c {
+						i = Composed(i, &listIterator{coords: h, i: -1})
+					}
+				}
+			}
+			return i
+		},
+		all: func() Iterator { return &allListsIterator{houses: houses, i: -1} },
+	}
+	return newLayout(rows, columns, custom), nil
+}
+
+// iterating a fixed list of 9 cells
+type listIterator struct {
+	coords [9]Coord
+	i      int
+}
+
+func (i *listIterator) Next() bool {
+	i.i++
+	return i.i < 9
+}
+
+func (i listIterator) Value() any {
+	return i.coords[i.i]
+}
+
+func (i *listIterator) Reset() {
+	i.i = -1
+}
+
+// iterator yielding a list iterator for each of a list of houses
+type allListsIterator struct {
+	houses [][9]Coord
+	i      int
+}
+
+func (i *allListsIterator) Next() bool {
+	i.i++
+	return i.i < len(i.houses)
+}
+
+func (i allListsIterator) Value() any {
+	return &listIterator{coords: i.houses[i.i], i: -1}
+}
+
+func (i *allListsIterator) Reset() {
+	i.i = -1
+}
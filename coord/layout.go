@@ -0,0 +1,215 @@
+package coord
+
+import (
+	"fmt"
+	"slices"
+)
+
+// A Layout is the set of houses of a sudoku variant. Every house has to hold
+// each digit exactly once. Solving techniques that work in terms of houses
+// and peers work with any layout.
+//
+// A variant can have rules beyond its houses, linking cells that can't hold
+// the same digit without sharing a house, like anti-knight. The linked cells
+// are peers too. The cages of killer link their cells, their sums are up to
+// the boards and solvers.
+//
+// Layouts are small values that are cheap to copy along with boards, the lookup tables are shared.
+type Layout struct {
+	kinds []houseKind
+	*tables
+}
+
+// lookup tables of a layout
+type tables struct {
+	houses  [][9]int     // cell indices of every house, in the order of Houses
+	names   []string     // names of the houses, like "row 3"
+	kindOf  []string     // names of the kinds of the houses, like "row"
+	numbers []int        // numbers of the houses within their kind, counting from 1
+	peers   [9 * 9][]int // cell indices of the cells sharing a house with a cell, without the cell itself
+	of      [9 * 9][]int // indices into houses of the houses containing a cell
+
+	houseSets []Set      // cells of every house
+	peerSets  [9 * 9]Set // cells sharing a house or linked by a rule with a cell, without the cell itself
+
+	links   [9 * 9][]int // cell indices of the cells linked by a rule of the variant with a cell, without a house
+	rules   bool         // there are rules beyond the houses
+	knights bool         // cells a knight's move apart are linked
+
+	cages     []Cage     // killer cages
+	cageCells [][]int    // cell indices of the cages
+	cageOf    [9 * 9]int // index into cages of the cage of a cell, -1 for none
+}
+
+// the most houses a layout can have
+const MaxHouses = 4 * 9
+
+// layout with the given kinds of houses
+func newLayout(kinds ...houseKind) Layout {
+	l := Layout{kinds: kinds, tables: &tables{}}
+	for ix := range l.cageOf {
+		l.cageOf[ix] = -1
+	}
+
+	for _, k := range kinds {
+		i := k.all()
+		for m := 1; i.Next(); m++ {
+			r := i.Value().(Iterator)
+			h := [9]int{}
+			for n := 0; r.Next(); n++ {
+				h[n] = Ctoi(r.Value().(Coord))
+			}
+			l.houses = append(l.houses, h)
+			l.names = append(l.names, fmt.Sprintf("%s %d", k.name, m))
+			l.kindOf = append(l.kindOf, k.name)
+			l.numbers = append(l.numbers, m)
+		}
+	}
+	if len(l.houses) > MaxHouses {
+		panic("too many houses in layout")
+	}
+
+	for n, h := range l.houses {
+		hs := Set{}
+		for _, ix := range h {
+			hs.Add(ix)
+		}
+		l.houseSets = append(l.houseSets, hs)
+
+		for _, ix := range h {
+			l.of[ix] = append(l.of[ix], n)
+			l.peerSets[ix] = l.peerSets[ix].Or(hs)
+			for _, p := range h {
+				if p != ix && !slices.Contains(l.peers[ix], p) {
+					l.peers[ix] = append(l.peers[ix], p)
+				}
+			}
+		}
+	}
+	for ix := range l.peerSets {
+		l.peerSets[ix].Remove(ix)
+	}
+	return l
+}
+
+// a kind of house, like rows or boxes
+type houseKind struct {
+	name string                 // name of a house of this kind
+	of   func(c Coord) Iterator // the house of this kind containing c
+	all  func() Iterator        // iterator yielding iterators for every house of this kind
+}
+
+var (
+	rows = houseKind{
+		name: "row",
+		of:   func(c Coord) Iterator { return Row(c) },
+		all:  func() Iterator { return AllRows() },
+	}
+	columns = houseKind{
+		name: "column",
+		of:   func(c Coord) Iterator { return Column(c) },
+		all:  func() Iterator { return AllColumns() },
+	}
+	boxes = houseKind{
+		name: "box",
+		of:   func(c Coord) Iterator { return Box(c) },
+		all:  func() Iterator { return AllBoxes() },
+	}
+
+	disjointGroups = houseKind{
+		name: "disjoint group",
+		of:   func(c Coord) Iterator { return DisjointGroup(c) },
+		all:  func() Iterator { return AllDisjointGroups() },
+	}
+
+	diagonals = houseKind{
+		name: "diagonal",
+		of: func(c Coord) Iterator {
+			var i Iterator = &emptyIterator{}
+			if c.X == c.Y {
+				i = Diagonal()
+			}
+			if c.X+c.Y == 8 {
+				i = Composed(i, AntiDiagonal())
+			}
+			return i
+		},
+		all: func() Iterator { return AllDiagonals() },
+	}
+
+	windows = houseKind{
+		name: "window",
+		of:   func(c Coord) Iterator { return Window(c) },
+		all:  func() Iterator { return AllWindows() },
+	}
+)
+
+// the classic sudoku layout with rows, columns and 3x3 boxes
+var Standard = newLayout(rows, columns, boxes)
+
+// the classic layout extended with the 9 disjoint groups: cells occupying the same position within each box
+var DisjointGroups = newLayout(rows, columns, boxes, disjointGroups)
+
+// sudoku-X: the classic layout extended with the 2 main diagonals
+var X = newLayout(rows, columns, boxes, diagonals)
+
+// windoku: the classic layout extended with 4 extra 3x3 windows
+var Windoku = newLayout(rows, columns, boxes, windows)
+
+// iterator yielding all cells that share a house with c or are linked with it, including c itself, possibly multiple
+// times
+func (l Layout) Peers(c Coord) Iterator {
+	i := l.kinds[0].of(c)
+
+	for _, k := range l.kinds[1:] {
+		i = Composed(i, k.of(c))
+	}
+	if ls := l.links[Ctoi(c)]; len(ls) > 0 {
+		i = Composed(i, &indexIterator{ixs: ls, i: -1})
+	}
+	return i
+}
+
+// iterator that yields house iterators, one for each house of the layout
+func (l Layout) Houses() Iterator {
+	i := l.kinds[0].all()
+
+	for _, k := range l.kinds[1:] {
+		i = Composed(i, k.all())
+	}
+	return i
+}
+
+// cell indices, as in Ctoi, of every house in the order of Houses
+//
+// the returned slice is shared and must not be modified
+func (l Layout) HouseIndices() [][9]int { return l.houses }
+
+// cell indices, as in Ctoi, of the cells sharing a house with the cell at index i, without i itself, followed by the
+// cells linked with it
+//
+// the returned slice is shared and must not be modified
+func (l Layout) PeerIndices(i int) []int { return l.peers[i] }
+
+// indices into HouseIndices of the houses containing the cell at index i
+//
+// the returned slice is shared and must not be modified
+func (l Layout) HousesOf(i int) []int { return l.of[i] }
+
+// name of house h, like "row 3", counting from 1 within the kind of house
+func (l Layout) HouseName(h int) string { return l.names[h] }
+
+// name of the kind of house h, like "row"
+func (l Layout) Kind(h int) string { return l.kindOf[h] }
+
+// cells of house h, in the order of HouseIndices
+func (l Layout) HouseSet(h int) Set { return l.houseSets[h] }
+
+// cells of all houses, in the order of HouseIndices
+func (l Layout) HouseSets() []Set { return l.houseSets }
+
+// cells sharing a house with the cell at index i or linked with it, without i itself
+func (l Layout) PeerSet(i int) Set { return l.peerSets[i] }
+
+// the zero Layout, without houses, that a zero value board has
+func (l Layout) IsZero() bool { return l.tables == nil }
@@ -0,0 +1,137 @@
+package coord
+
+import (
+	"fmt"
+	"slices"
+)
+
+// the knight's moves on the board
+var knightMoves = [8][2]dim{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+
+// anti-knight: the classic layout where cells a knight's move apart can't hold the same digit
+var AntiKnight = antiKnight()
+
+func antiKnight() Layout {
+	l := newLayout(rows, columns, boxes)
+	l.knights = true
+	for ix := range 9 * 9 {
+		c := Itoc(ix)
+		for _, m := range knightMoves {
+			if p, err := CtoiChecked(Coord{c.X + m[0], c.Y + m[1]}); err == nil {
+				l.link(ix, p)
+			}
+		}
+	}
+	return l
+}
+
+// a killer cage: cells holding distinct digits that add up to Sum
+type Cage struct {
+	Cells []Coord
+	Sum   int
+}
+
+// killer: the classic layout with cages, the cells of a cage can't hold the same digit and add up to its sum
+//
+// the cages don't have to cover the board. A cell can only be in one cage, and the sum of a cage of n cells has to be
+// within what n distinct digits can add up to.
+func Killer(cages ...Cage) (Layout, error) {
+	l := newLayout(rows, columns, boxes)
+	for k, cg := range cages {
+		n := len(cg.Cells)
+		if n < 1 || n > 9 {
+			return Layout{}, fmt.Errorf("cage %d: %d cells, expected 1 to 9", k+1, n)
+		}
+		if lo, hi := n*(n+1)/2, n*(19-n)/2; cg.Sum < lo || cg.Sum > hi {
+			return Layout{}, fmt.Errorf("cage %d: sum %d, %d cells add up to %d to %d", k+1, cg.Sum, n, lo, hi)
+		}
+		ixs := []int{}
+		for _, c := range cg.Cells {
+			ix, err := CtoiChecked(c)
+			if err != nil {
+				return Layout{}, fmt.Errorf("cage %d: %w", k+1, err)
+			}
+			if l.cageOf[ix] >= 0 {
+				return Layout{}, fmt.Errorf("cage %d: r%dc%d is in cage %d", k+1, c.Y+1, c.X+1, l.cageOf[ix]+1)
+			}
+			l.cageOf[ix] = k
+			ixs = append(ixs, ix)
+		}
+		for i, a := range ixs {
+			for _, b := range ixs[i+1:] {
+				l.link(a, b)
+			}
+		}
+		l.cages = append(l.cages, Cage{Cells: slices.Clone(cg.Cells), Sum: cg.Sum})
+		l.cageCells = append(l.cageCells, ixs)
+	}
+	l.rules = l.rules || len(cages) > 0
+	return l, nil
+}
+
+// the killer cages of the layout, nil if it has none
+//
+// the returned slice is shared and must not be modified
+func (l Layout) Cages() []Cage { return l.cages }
+
+// cell indices, as in Ctoi, of the cells of cage k, in the order of the Cells of the cage
+//
+// the returned slice is shared and must not be modified
+func (l Layout) CageIndices(k int) []int { return l.cageCells[k] }
+
+// index into Cages of the cage of the cell at index i, -1 if it's in none
+func (l Layout) CageOf(i int) int { return l.cageOf[i] }
+
+// makes the cells at a and b peers without a house, for a rule of the variant that keeps them from holding the same
+// digit. Only for building a layout, the tables are shared once it's built.
+func (l Layout) link(a, b int) {
+	if l.peerSets[a].Has(b) {
+		return
+	}
+	for _, e := range [2][2]int{{a, b}, {b, a}} {
+		l.links[e[0]] = append(l.links[e[0]], e[1])
+		l.peers[e[0]] = append(l.peers[e[0]], e[1])
+		l.peerSets[e[0]].Add(e[1])
+	}
+	l.rules = true
+}
+
+// cell indices, as in Ctoi, of the cells that can't hold the digit of the cell at index i by a rule of the variant,
+// without sharing a house with it, like the knight's moves of anti-knight
+//
+// the links are in PeerIndices and PeerSet too. The returned slice is shared and must not be modified
+func (l Layout) Links(i int) []int { return l.links[i] }
+
+// the layout has no rules but its houses, every candidate elimination follows from a digit placed once per house
+//
+// solvers that work in terms of houses only, like dancing links, need this
+func (l Layout) HousesOnly() bool { return !l.rules }
+
+// the rule keeping the cells at indices i and p from holding the same digit without sharing a house, like "a knight's
+// move away" or "in cage 3", empty if they share a house or aren't linked
+func (l Layout) Rule(i, p int) string {
+	switch {
+	case !slices.Contains(l.links[i], p):
+		return ""
+	case l.cageOf[i] >= 0 && l.cageOf[i] == l.cageOf[p]:
+		return fmt.Sprintf("in cage %d", l.cageOf[i]+1)
+	case l.knights:
+		return "a knight's move away"
+	}
+	return ""
+}
+
+// iterating a list of cell indices
+type indexIterator struct {
+	ixs []int
+	i   int
+}
+
+func (i *indexIterator) Next() bool {
+	i.i++
+	return i.i < len(i.ixs)
+}
+
+func (i indexIterator) Value() any { return Itoc(i.ixs[i.i]) }
+
+func (i *indexIterator) Reset() { i.i = -1 }
@@ -0,0 +1,51 @@
+package coord
+
+import "math/bits"
+
+// A set of cells as an 81 bit bitmap in two words, bit i is the cell at index i as in Ctoi
+//
+// Set operations work on both words at once, so whole houses or peer groups are combined in a few instructions.
+type Set [2]uint64
+
+// set of all 81 cells
+func Full() Set { return Set{^uint64(0), 1<<(9*9-64) - 1} }
+
+// adds the cell at index i
+func (s *Set) Add(i int) { s[i>>6] |= 1 << (i & 63) }
+
+// removes the cell at index i
+func (s *Set) Remove(i int) { s[i>>6] &^= 1 << (i & 63) }
+
+// does the set contain the cell at index i?
+func (s Set) Has(i int) bool { return s[i>>6]&(1<<(i&63)) != 0 }
+
+// cells in both s and o
+func (s Set) And(o Set) Set { return Set{s[0] & o[0], s[1] & o[1]} }
+
+// cells in s but not in o
+func (s Set) AndNot(o Set) Set { return Set{s[0] &^ o[0], s[1] &^ o[1]} }
+
+// cells in either s or o
+func (s Set) Or(o Set) Set { return Set{s[0] | o[0], s[1] | o[1]} }
+
+// number of cells in the set
+func (s Set) Count() int { return bits.OnesCount64(s[0]) + bits.OnesCount64(s[1]) }
+
+// is the set empty?
+func (s Set) IsEmpty() bool { return s[0]|s[1] == 0 }
+
+// does the set have exactly one cell?
+func (s Set) IsSingle() bool {
+	return (s[0] != 0 && s[0]&(s[0]-1) == 0 && s[1] == 0) || (s[1] != 0 && s[1]&(s[1]-1) == 0 && s[0] == 0)
+}
+
+// the lowest cell index in the set, -1 for the empty set
+func (s Set) First() int {
+	switch {
+	case s[0] != 0:
+		return bits.TrailingZeros64(s[0])
+	case s[1] != 0:
+		return 64 + bits.TrailingZeros64(s[1])
+	}
+	return -1
+}
@@ -0,0 +1,98 @@
+package coord
+
+// iterating the main diagonal from the top left corner to the bottom right
+func Diagonal() *diagonalIterator { return &diagonalIterator{i: -1} }
+
+// iterating the anti diagonal from the top right corner to the bottom left
+func AntiDiagonal() *diagonalIterator { return &diagonalIterator{anti: true, i: -1} }
+
+// iterator that yields the 2 diagonal iterators
+func AllDiagonals() *allDiagonalsIterator { return &allDiagonalsIterator{i: -1} }
+
+// coordinates for the cells of the windoku window containing c, or nothing if c is not in a window
+func Window(c Coord) Iterator {
+	if c.X%4 == 0 || c.Y%4 == 0 {
+		return &emptyIterator{}
+	}
+	return window(c.X-(c.X-1)%4, c.Y-(c.Y-1)%4)
+}
+
+// iterator that yields window iterators, one for each of the 4 windoku windows
+func AllWindows() *allWindowsIterator { return &allWindowsIterator{i: -1} }
+
+// windoku window with top left corner at x, y
+func window(x, y dim) *boxIterator {
+	i := boxIterator{base: Coord{x, y}, i: -1}
+
+	n := 0
+	for dx := dim(0); dx < 3; dx++ {
+		for dy := dim(0); dy < 3; dy++ {
+			i.coords[n] = Coord{x + dx, y + dy}
+			n++
+		}
+	}
+	return &i
+}
+
+type diagonalIterator struct {
+	anti bool
+	i    dim
+}
+
+func (i *diagonalIterator) Next() bool {
+	i.i++
+	return i.i < 9
+}
+
+func (i diagonalIterator) Value() any {
+	if i.anti {
+		return Coord{8 - i.i, i.i}
+	}
+	return Coord{i.i, i.i}
+}
+
+func (i *diagonalIterator) Reset() {
+	i.i = -1
+}
+
+type allDiagonalsIterator struct{ i dim }
+
+func (i *allDiagonalsIterator) Next() bool {
+	i.i++
+	return i.i < 2
+}
+
+func (i allDiagonalsIterator) Value() any {
+	if i.i == 0 {
+		return Diagonal()
+	}
+	return AntiDiagonal()
+}
+
+func (i *allDiagonalsIterator) Reset() {
+	i.i = -1
+}
+
+type allWindowsIterator struct{ i dim }
+
+func (i *allWindowsIterator) Next() bool {
+	i.i++
+	return i.i < 4
+}
+
+func (i allWindowsIterator) Value() any {
+	return window(1+i.i%2*4, 1+i.i/2*4)
+}
+
+func (i *allWindowsIterator) Reset() {
+	i.i = -1
+}
+
+// iterator yielding nothing, for cells outside of a partial house kind
+type emptyIterator struct{}
+
+func (i *emptyIterator) Next() bool { return false }
+
+func (i emptyIterator) Value() any { return nil }
+
+func (i *emptyIterator) Reset() {}
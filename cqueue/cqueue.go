@@ -1,32 +1,18 @@
 // priority queue for cells
+//
+// Deprecated: the queue is an implementation detail of the logic solver in package solve, it will be removed in the
+// next major version. Use container/heap with a queue of your own.
 package cqueue
 
-import "github.com/phaul/sudoku/coord"
+import "github.com/phaul/sudoku/internal/cqueue"
 
 // priority queue for coordinates based on the amount of candidates
-type PrioCoord struct {
-	Count int
-	Coord coord.Coord
-}
+//
+// Deprecated: see the package documentation.
+type PrioCoord = cqueue.PrioCoord
 
-type Queue []PrioCoord
+// Deprecated: see the package documentation.
+type Queue = cqueue.Queue
 
-func New() Queue { return make(Queue, 0, 16) }
-
-func (q Queue) Len() int           { return len(q) }
-func (q Queue) Less(i, j int) bool { return q[i].Count < q[j].Count }
-func (q Queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
-
-func (q *Queue) Push(x any) {
-	// Push and Pop use pointer receivers because they modify the slice's length,
-	// not just its contents.
-	*q = append(*q, x.(PrioCoord))
-}
-
-func (q *Queue) Pop() any {
-	old := *q
-	n := len(old)
-	x := old[n-1]
-	*q = old[0 : n-1]
-	return x
-}
+// Deprecated: see the package documentation.
+func New() Queue { return cqueue.New() }
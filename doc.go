@@ -0,0 +1,25 @@
+// Command sudoku solves, generates, rates, verifies and plays sudoku puzzles, and serves them over http, the command
+// line on top of the library packages of the module:
+//
+//   - cell and coord: digits, candidates, coordinates and the houses of the variant layouts
+//   - coord/coordtest: conformance checks for the tests of new iterators and layouts
+//   - board: the board with its candidates, edits, validation and rendering
+//   - solve: the logic, dancing links and compact solvers, with their traces
+//   - gen: puzzle generation
+//   - rate: difficulty rating
+//   - formats: line, text grid, hodoku, simple sudoku, sukaku, killer, sdm and puzzle bank, OpenSudoku, pdf and json
+//     trace formats
+//   - play: play sessions
+//
+// # Compatibility
+//
+// The module follows semantic import versioning. The exported API of the packages above doesn't change incompatibly
+// within v1. Names that are replaced stay as shims marked Deprecated, pointing to their replacement, until the next
+// major version. Packages under internal are not part of the API.
+//
+// # Build tags
+//
+//   - wide: cells of up to 25 values
+//   - lowmem: the compact solver is the only backend of solve.Auto and solve.Solvers, for constrained targets like
+//     TinyGo builds for e-ink devices. Nothing is traced, so the solving steps are empty.
+package main
@@ -0,0 +1,8 @@
+package main
+
+import "errors"
+
+var (
+	errTooFew = errors.New("puzzle has too few clues") // less clues than a unique puzzle can have
+	errMemory = errors.New("memory limit exceeded")    // the heap grew over -max-memory
+)
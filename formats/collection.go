@@ -0,0 +1,275 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+)
+
+// a puzzle of a collection file
+type Entry struct {
+	File   string
+	Line   int
+	Puzzle string  // the puzzle in the 81 character line format
+	ID     string  // id in the puzzle bank, if the file is one
+	Rating float64 // rating in the puzzle bank, if the file is one
+	Meta   Metadata
+}
+
+// attribution of a puzzle
+//
+// in collection files '# key: value' header lines set a field for the puzzles after them, other comments are ignored
+type Metadata struct {
+	Source  string
+	Author  string
+	License string
+	Date    string
+	Rating  string // rating given by the source, in its own scale
+}
+
+// header keys of the metadata fields, in the order they are written
+var MetadataKeys = []string{"source", "author", "license", "date", "rating"}
+
+// the field of key, nil for unknown keys
+func (m *Metadata) Field(key string) *string {
+	switch key {
+	case "source":
+		return &m.Source
+	case "author":
+		return &m.Author
+	case "license":
+		return &m.License
+	case "date":
+		return &m.Date
+	case "rating":
+		return &m.Rating
+	}
+	return nil
+}
+
+// applies the header line l starting with '#', returning false if it's a plain comment
+func (m *Metadata) Header(l string) bool {
+	k, v, ok := strings.Cut(strings.TrimPrefix(l, "#"), ":")
+	if !ok {
+		return false
+	}
+	f := m.Field(strings.ToLower(strings.TrimSpace(k)))
+	if f == nil {
+		return false
+	}
+	*f = strings.ToValidUTF8(strings.TrimSpace(v), "\uFFFD")
+	return true
+}
+
+// the fields both m and o agree on, the others left empty
+func (m Metadata) Common(o Metadata) Metadata {
+	for _, k := range MetadataKeys {
+		if f := m.Field(k); *f != *o.Field(k) {
+			*f = ""
+		}
+	}
+	return m
+}
+
+// parses a line of the sudoku exchange puzzle bank: an id, the puzzle in the 81 character line format and a numeric
+// rating separated by whitespace
+func ParseBank(s string) (Entry, error) {
+	fs := strings.Fields(s)
+	if len(fs) != 3 {
+		return Entry{}, fmt.Errorf("puzzle bank line has %d fields instead of 3", len(fs))
+	}
+
+	r, err := strconv.ParseFloat(fs[2], 64)
+	if err != nil || math.IsNaN(r) || math.IsInf(r, 0) {
+		return Entry{}, fmt.Errorf("invalid rating %q", fs[2])
+	}
+	return Entry{ID: fs[0], Puzzle: fs[1], Rating: r}, nil
+}
+
+// calls f with every puzzle of file fn, holding sdm or puzzle bank lines or an OpenSudoku collection, stopping on the
+// first error f returns
+//
+// lines that can't be parsed are passed to f with the parse error. puzzles carry the metadata of the header lines
+// before them. The puzzles of an OpenSudoku collection carry its metadata, and the number of the game in the collection
+// as their line.
+func ReadPuzzles(fn string, f func(e Entry, b board.Board, err error) error) error {
+	r, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return ReadPuzzlesFrom(fn, r, f)
+}
+
+// ReadPuzzles from r, reporting name as the file of the entries
+func ReadPuzzlesFrom(name string, r io.Reader, f func(e Entry, b board.Board, err error) error) error {
+	br := bufio.NewReader(r)
+	if isXML(br) {
+		c, err := ReadOpenSudoku(br)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for n, g := range c.Games {
+			b, err := ParseLine(coord.Standard, g.Data)
+			if err := f(Entry{File: name, Line: n + 1, Puzzle: g.Data, Meta: c.Meta()}, b, err); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var ferr error
+	err := scan(name, br, func(e Entry, b board.Board, err error) bool {
+		ferr = f(e, b, err)
+		return ferr == nil
+	})
+	if ferr != nil {
+		return ferr
+	}
+	return err
+}
+
+// the input of r starts with an xml tag, after blanks
+func isXML(r *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		p, err := r.Peek(n)
+		if err != nil {
+			return false
+		}
+		switch p[n-1] {
+		case ' ', '\t', '\r', '\n':
+		case '<':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// the puzzles of r, holding sdm or puzzle bank lines, parsed one line at a time as the sequence is iterated
+//
+// only the line being parsed is held in memory, so r can be of any size. a line that can't be parsed yields its error
+// with the line number, and the sequence goes on. a read error ends the sequence.
+func Stream(r io.Reader) iter.Seq2[board.Board, error] {
+	return func(yield func(board.Board, error) bool) {
+		err := scan("", r, func(e Entry, b board.Board, err error) bool {
+			if err != nil {
+				err = fmt.Errorf("line %d: %w", e.Line, err)
+			}
+			return yield(b, err)
+		})
+		if err != nil {
+			yield(board.Board{}, err)
+		}
+	}
+}
+
+// calls yield with every puzzle of r until it returns false, returning the read error
+func scan(name string, r io.Reader, yield func(e Entry, b board.Board, err error) bool) error {
+	s := bufio.NewScanner(r)
+	meta := Metadata{}
+	n := 0
+	for s.Scan() {
+		n++
+		l := strings.TrimSpace(s.Text())
+		if l == "" {
+			continue
+		}
+		if strings.HasPrefix(l, "#") {
+			meta.Header(l)
+			continue
+		}
+
+		e := Entry{Puzzle: l}
+		var err error
+		if strings.ContainsAny(l, " \t") {
+			e, err = ParseBank(l)
+		}
+		e.File, e.Line, e.Meta = name, n, meta
+
+		b := board.Board{}
+		if err == nil {
+			b, err = ParseLine(coord.Standard, e.Puzzle)
+		}
+		if !yield(e, b, err) {
+			return nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		if name == "" {
+			return fmt.Errorf("line %d: %w", n+1, err)
+		}
+		return fmt.Errorf("%s:%d: %w", name, n+1, err)
+	}
+	return nil
+}
+
+// writes the puzzles of es to w in the 81 character line format, with header lines wherever the metadata changes, so
+// that ReadPuzzles reads back the same metadata
+func WriteCollection(w io.Writer, es []Entry) error {
+	bw := bufio.NewWriter(w)
+	meta := Metadata{}
+	for _, e := range es {
+		for _, k := range MetadataKeys {
+			if v := *e.Meta.Field(k); v != *meta.Field(k) {
+				fmt.Fprintf(bw, "# %s: %s\n", k, v)
+			}
+		}
+		meta = e.Meta
+		fmt.Fprintln(bw, e.Puzzle)
+	}
+	return bw.Flush()
+}
+
+// copies the collection in r to w with every puzzle replaced by f of it
+//
+// everything else is kept as it was: comments, header lines, blank lines, the ids and ratings of puzzle bank lines
+// and whether empty cells are written as '.' or '0'. A line that can't be parsed is an error with its line number.
+func Rewrite(w io.Writer, r io.Reader, f func(b *board.Board) board.Board) error {
+	s := bufio.NewScanner(r)
+	bw := bufio.NewWriter(w)
+	n := 0
+	for s.Scan() {
+		n++
+		l := s.Text()
+		if t := strings.TrimSpace(l); t == "" || strings.HasPrefix(t, "#") {
+			fmt.Fprintln(bw, l)
+			continue
+		}
+
+		e := Entry{Puzzle: strings.TrimSpace(l)}
+		var err error
+		if strings.ContainsAny(e.Puzzle, " \t") {
+			e, err = ParseBank(l)
+		}
+		b := board.Board{}
+		if err == nil {
+			b, err = ParseLine(coord.Standard, e.Puzzle)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+
+		at := strings.Index(l, e.ID) + len(e.ID)
+		at += strings.Index(l[at:], e.Puzzle)
+		p := f(&b)
+		out := p.Line()
+		if strings.Contains(e.Puzzle, "0") {
+			out = strings.ReplaceAll(out, ".", "0")
+		}
+		fmt.Fprintln(bw, l[:at]+out+l[at+len(e.Puzzle):])
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", n+1, err)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,141 @@
+package formats_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/formats"
+)
+
+const puzzle = "4.....8.5.3..........7......2.....6.....8.4......1.......6.3.7.5..2.....1.4......"
+
+// input every parser has to reject: oversized, invalid utf-8, and too few or too many symbols
+var adversarial = []string{
+	"",
+	strings.Repeat("1", 1<<16),
+	strings.Repeat("|1", 1<<15),
+	strings.Repeat(puzzle+"\n", 1<<10),
+	"\xff\xfe" + puzzle[2:],
+	puzzle[:40] + "\xc3\x28" + puzzle[42:],
+	puzzle[:80],
+	puzzle + ".",
+	strings.Repeat("\x00", 9*9),
+}
+
+// adds good and bad to the corpus of f, failing if parse accepts one of the bad ones
+func seed(f *testing.F, parse func(string) error, good, bad []string) {
+	f.Helper()
+	for _, s := range good {
+		f.Add(s)
+	}
+	for _, s := range append(bad, adversarial...) {
+		f.Add(s)
+		if err := parse(s); err == nil {
+			f.Errorf("parsed %.100q without an error", s)
+		}
+	}
+}
+
+// b is a valid board, as every successful parse has to return
+func valid(t *testing.T, b board.Board, s string) {
+	t.Helper()
+	if err := b.Validate(); err != nil {
+		t.Errorf("%.100q parsed to an invalid board: %v", s, err)
+	}
+}
+
+func FuzzParseLine(f *testing.F) {
+	parse := func(s string) error { _, err := formats.ParseLine(coord.Standard, s); return err }
+	seed(f, parse, []string{puzzle, strings.ReplaceAll(puzzle, ".", "0")},
+		[]string{"11" + puzzle[2:], "4" + puzzle[2:] + "x"})
+
+	f.Fuzz(func(t *testing.T, s string) {
+		b, err := formats.ParseLine(coord.Standard, s)
+		if err != nil {
+			return
+		}
+		valid(t, b, s)
+		if got := b.Line(); got != strings.ReplaceAll(s, "0", ".") {
+			t.Errorf("%q parsed to %q", s, got)
+		}
+	})
+}
+
+func FuzzParseGrid(f *testing.F) {
+	b, err := formats.ParseLine(coord.Standard, puzzle)
+	if err != nil {
+		f.Fatal(err)
+	}
+	grid := strings.Builder{}
+	if err := b.Render(&grid, board.Style{}); err != nil {
+		f.Fatal(err)
+	}
+	rows := []string{}
+	for r := range 9 {
+		rows = append(rows, puzzle[r*9:r*9+9])
+	}
+	parse := func(s string) error { _, err := formats.ParseGrid(coord.Standard, s); return err }
+	seed(f, parse, []string{grid.String(), strings.Join(rows, "\n")},
+		[]string{strings.Join(rows[:8], "\n"), strings.Join(append(rows, rows[0]), "\n"), "11" + grid.String()[2:],
+			strings.Join(append([]string{rows[0] + "."}, rows[1:]...), "\n")})
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if b, err := formats.ParseGrid(coord.Standard, s); err == nil {
+			valid(t, b, s)
+		}
+	})
+}
+
+func FuzzParseSukaku(f *testing.F) {
+	b, err := formats.ParseLine(coord.Standard, puzzle)
+	if err != nil {
+		f.Fatal(err)
+	}
+	b.RecomputeCandidates()
+	s := formats.SukakuLine(&b)
+	parse := func(s string) error { _, err := formats.ParseSukaku(coord.Standard, s); return err }
+	seed(f, parse, []string{s, strings.ReplaceAll(s, ".", "0")},
+		[]string{s[:728], s + ".", "2" + s[1:], "\xff" + s[1:]})
+
+	f.Fuzz(func(t *testing.T, s string) {
+		b, err := formats.ParseSukaku(coord.Standard, s)
+		if err != nil {
+			return
+		}
+		want := strings.Join(strings.Fields(strings.ReplaceAll(s, "0", ".")), "")
+		if got := formats.SukakuLine(&b); got != want {
+			t.Errorf("%.100q parsed to %.100q", s, got)
+		}
+	})
+}
+
+func FuzzParseKiller(f *testing.F) {
+	dots := strings.Repeat(".", 9*9)
+	good := dots + "\n3 r1c1 r1c2\n17 r9c8 r9c9"
+	parse := func(s string) error { _, err := formats.ParseKiller(s); return err }
+	seed(f, parse, []string{good, puzzle + "\n15 r5c5 r5c6"},
+		[]string{dots + "\n3 r1c1 r1c1", dots + "\n3 r1c1 r0c2", dots + "\nr1c1 3", dots + "\n99 r1c1 r1c2",
+			dots + "\n3 r1c1\n4 r1c1 r1c2", dots[:80] + "\n3 r1c1 r1c2", dots + "\n3 r1c1 r1c\xff"})
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if b, err := formats.ParseKiller(s); err == nil {
+			valid(t, b, s)
+		}
+	})
+}
+
+func FuzzParseHodoku(f *testing.F) {
+	parse := func(s string) error { _, _, err := formats.ParseHodoku(coord.Standard, s); return err }
+	line := ":0000:x:" + puzzle
+	seed(f, parse, []string{line + ":::", ":0000:x:+4" + puzzle[1:] + ":111 212::", ":0000:1:" + puzzle + ":::116:"},
+		[]string{":0000:x:" + puzzle[:80] + ":::", line + "1:::", line + ":011::", ":0000:x:44" + puzzle[2:] + ":::",
+			line[1:] + ":::", line + ":1\xff1::", line + "+:::"})
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if b, _, err := formats.ParseHodoku(coord.Standard, s); err == nil {
+			valid(t, b, s)
+		}
+	})
+}
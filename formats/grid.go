@@ -0,0 +1,78 @@
+package formats
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// color escapes of a themed grid
+var sgr = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// parses the text grid Board.Render writes, digits are givens and '0', '.', '·' or a blank are empty cells
+//
+// the parsing is lenient: separator lines of '+', '-' and '=' and blank lines are skipped, and so are colors. In a row
+// the cells of a box between two '|' are either written one per column, a blank for an empty cell, or separated by
+// blanks. Rows without '|' are the 9 cells separated by blanks or not at all. Givens repeating a value in a house are a
+// *board.InvalidPuzzleError.
+func ParseGrid(l coord.Layout, s string) (board.Board, error) {
+	v := [9 * 9]cell.ValT{}
+	ix := 0
+	for n, line := range strings.Split(sgr.ReplaceAllString(s, ""), "\n") {
+		line = strings.TrimRight(strings.ReplaceAll(line, "·", "."), " \t\r")
+		if strings.Trim(line, "+-=| \t") == "" {
+			continue
+		}
+		cells, err := gridRow(line)
+		if err == nil && ix == 9*9 {
+			err = fmt.Errorf("more than 9 rows")
+		}
+		if err != nil {
+			return board.Board{}, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		for _, ch := range []byte(cells) {
+			switch {
+			case ch == '.' || ch == '0' || ch == ' ':
+			case '1' <= ch && ch <= '9':
+				v[ix] = cell.ValT(ch - '0')
+			default:
+				return board.Board{}, fmt.Errorf("line %d: invalid character %q", n+1, ch)
+			}
+			ix++
+		}
+	}
+	if ix != 9*9 {
+		return board.Board{}, fmt.Errorf("grid has %d rows instead of 9", ix/9)
+	}
+
+	b := board.FromValues(l, v)
+	if err := b.Validate(); err != nil {
+		return board.Board{}, err
+	}
+	return b, nil
+}
+
+// the 9 cells of a row of the grid, a blank for an empty cell
+func gridRow(line string) (string, error) {
+	cells := ""
+	if strings.Contains(line, "|") {
+		for _, box := range strings.Split(line, "|") {
+			if len(box) != 3 {
+				box = strings.Join(strings.Fields(box), "")
+			}
+			if cells += box; len(cells) > 9 {
+				break // a long line is rejected below without joining all of it
+			}
+		}
+	} else {
+		cells = strings.Join(strings.Fields(line), "")
+	}
+	if len(cells) != 9 {
+		return "", fmt.Errorf("row has %d cells instead of 9", len(cells))
+	}
+	return cells, nil
+}
@@ -0,0 +1,212 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/solve"
+)
+
+// technique codes of the hodoku library format
+var hodokuCodes = map[solve.Technique]string{
+	solve.NakedSingle:  "0003",
+	solve.HiddenSingle: "0002",
+}
+
+// a step of a trace has no technique code in the hodoku library format, like a guess
+var ErrHodokuCode = errors.New("no hodoku library code")
+
+// a digit in a cell, written as digit, row, column in hodoku
+type Candidate struct {
+	Coord coord.Coord
+	Value cell.ValT
+}
+
+func (c Candidate) String() string { return fmt.Sprintf("%d%d%d", c.Value, c.Coord.Y+1, c.Coord.X+1) }
+
+// the step annotation of a hodoku library line
+type HodokuStep struct {
+	Code         string      // technique code
+	Digits       string      // digits the step is about
+	Eliminations []Candidate // candidates the step removes
+	Placements   []Candidate // values the step places
+}
+
+// the library line for b with the next step s
+//
+// placed values that are not givens are prefixed with '+', candidates that are not eliminated by the placed values
+// are listed as deleted
+func Hodoku(b *board.Board, s HodokuStep) string {
+	sb := strings.Builder{}
+
+	for ix, v := range b.Values() {
+		switch {
+		case v == 0:
+			sb.WriteByte('.')
+		case b.IsGiven(coord.Itoc(ix)):
+			fmt.Fprint(&sb, v)
+		default:
+			fmt.Fprintf(&sb, "+%d", v)
+		}
+	}
+
+	naive := *b
+	naive.RecomputeCandidates()
+	deleted := []Candidate{}
+	for ix := range 9 * 9 {
+		c := b.Cell(ix)
+		for v := cell.ValT(1); v <= 9; v++ {
+			if c.IsEmpty() && naive.Cell(ix).IsPossible(v) && !c.IsPossible(v) {
+				deleted = append(deleted, Candidate{coord.Itoc(ix), v})
+			}
+		}
+	}
+
+	return fmt.Sprintf(":%s:%s:%s:%s:%s:%s:", s.Code, s.Digits, sb.String(), candidates(deleted),
+		candidates(s.Eliminations), candidates(s.Placements))
+}
+
+// the steps of t as hodoku library lines, each with the board before the step
+//
+// the library has no code for a guess, a trace with guesses is an error wrapping ErrHodokuCode, with the lines of the
+// steps before the first guess
+func HodokuLines(b board.Board, t solve.Trace) ([]string, error) {
+	ls := []string{}
+
+	for _, st := range t {
+		code, ok := hodokuCodes[st.Technique]
+		if !ok {
+			return ls, fmt.Errorf("%v: %w", st, ErrHodokuCode)
+		}
+		c := Candidate{st.Coord, st.Value}
+		ls = append(ls, Hodoku(&b, HodokuStep{
+			Code:       code,
+			Digits:     fmt.Sprint(st.Value),
+			Placements: []Candidate{c},
+		}))
+		b.Fill(st.Coord, st.Value)
+	}
+	return ls, nil
+}
+
+// the lines of the steps of t before its first guess, as HodokuLines
+//
+// Deprecated: use HodokuLines, which reports a trace the library can't hold.
+func HodokuTrace(b board.Board, t solve.Trace) []string {
+	ls, _ := HodokuLines(b, t)
+	return ls
+}
+
+// technique names of the hodoku step notation
+var hodokuNames = map[solve.Technique]string{
+	solve.NakedSingle:  "Naked Single",
+	solve.HiddenSingle: "Hidden Single",
+	solve.Guess:        "Guess",
+}
+
+// the step s in the notation of hodoku and sudoku explainer, like "Hidden Single: 7 in r3c5 (block 2)"
+//
+// the house is only given for hidden singles, boxes are called blocks as in hodoku, other houses keep their names.
+func HodokuNotation(s solve.Step) string {
+	name, ok := hodokuNames[s.Technique]
+	if !ok {
+		name = s.Technique.String()
+	}
+	r := fmt.Sprintf("%s: %d in r%dc%d", name, s.Value, s.Coord.Y+1, s.Coord.X+1)
+	if s.House.Kind != "" {
+		kind := s.House.Kind
+		if kind == "box" {
+			kind = "block"
+		}
+		r += fmt.Sprintf(" (%s %d)", kind, s.House.Number)
+	}
+	return r
+}
+
+// parses a hodoku library line
+//
+// values prefixed with '+' are placed, the others are givens. the candidates are derived from the values, without the
+// deleted candidates of the line.
+func ParseHodoku(l coord.Layout, s string) (board.Board, HodokuStep, error) {
+	fs := strings.Split(s, ":")
+	if len(fs) < 5 || fs[0] != "" {
+		return board.Board{}, HodokuStep{}, fmt.Errorf("not a hodoku library line")
+	}
+
+	b := board.New(l)
+	step := HodokuStep{Code: fs[1], Digits: fs[2]}
+	ix := 0
+	for p := 0; p < len(fs[3]); p++ {
+		if ix >= 9*9 {
+			return board.Board{}, step, fmt.Errorf("puzzle has more than 81 cells")
+		}
+		given := true
+		if fs[3][p] == '+' && p+1 < len(fs[3]) {
+			given = false
+			p++
+		}
+		switch ch := fs[3][p]; {
+		case ch == '.' || ch == '0':
+		case '1' <= ch && ch <= '9' && given:
+			b.Give(coord.Itoc(ix), cell.ValT(ch-'0'))
+		case '1' <= ch && ch <= '9':
+			b.Fill(coord.Itoc(ix), cell.ValT(ch-'0'))
+		default:
+			return board.Board{}, step, fmt.Errorf("invalid character %q at %d in puzzle", ch, p+1)
+		}
+		ix++
+	}
+	if ix != 9*9 {
+		return board.Board{}, step, fmt.Errorf("puzzle has %d cells instead of 81", ix)
+	}
+	if err := b.Validate(); err != nil {
+		return board.Board{}, step, err
+	}
+
+	deleted, err := parseCandidates(fs[4])
+	if err != nil {
+		return board.Board{}, step, err
+	}
+	for _, c := range deleted {
+		b.Drop(coord.Ctoi(c.Coord), c.Value)
+	}
+
+	if len(fs) > 5 {
+		if step.Eliminations, err = parseCandidates(fs[5]); err != nil {
+			return board.Board{}, step, err
+		}
+	}
+	if len(fs) > 6 {
+		if step.Placements, err = parseCandidates(fs[6]); err != nil {
+			return board.Board{}, step, err
+		}
+	}
+	return b, step, nil
+}
+
+// space separated digit, row, column triplets
+func candidates(cs []Candidate) string {
+	ss := []string{}
+
+	for _, c := range cs {
+		ss = append(ss, c.String())
+	}
+	return strings.Join(ss, " ")
+}
+
+// parses space separated digit, row, column triplets
+func parseCandidates(s string) ([]Candidate, error) {
+	cs := []Candidate{}
+
+	for n, f := range strings.Fields(s) {
+		if len(f) != 3 || strings.Trim(f, "123456789") != "" {
+			return nil, fmt.Errorf("invalid candidate %q at %d", f, n+1)
+		}
+		cs = append(cs, Candidate{coord.Itoc(int(f[1]-'1')*9 + int(f[2]-'1')), cell.ValT(f[0] - '0')})
+	}
+	return cs, nil
+}
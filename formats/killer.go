@@ -0,0 +1,84 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+)
+
+// parses a killer puzzle: the givens in the 81 character line format on the first line, then a line per cage of its
+// sum and cells, like 15 r1c1 r1c2 r2c1
+//
+// the layout of the board is the classic one with the cages, as coord.Killer builds it. Most killer puzzles have no
+// givens, the line is all '.' or '0' then.
+func ParseKiller(s string) (board.Board, error) {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	cages := []coord.Cage{}
+	for i, ln := range lines[1:] {
+		if ln = strings.TrimSpace(ln); ln == "" {
+			continue
+		}
+		cg, err := parseCage(ln)
+		if err != nil {
+			return board.Board{}, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		cages = append(cages, cg)
+	}
+	l, err := coord.Killer(cages...)
+	if err != nil {
+		return board.Board{}, err
+	}
+	return ParseLine(l, strings.TrimSpace(lines[0]))
+}
+
+// parses the line of a cage, its sum then its cells in rXcY
+func parseCage(s string) (coord.Cage, error) {
+	fs := strings.Fields(s)
+	if len(fs) < 2 {
+		return coord.Cage{}, fmt.Errorf("invalid cage %q, expected its sum then its cells like 15 r1c1 r1c2", s)
+	}
+	sum, err := strconv.Atoi(fs[0])
+	if err != nil {
+		return coord.Cage{}, fmt.Errorf("invalid cage sum %q", fs[0])
+	}
+	cg := coord.Cage{Sum: sum}
+	for _, f := range fs[1:] {
+		if len(f) != 4 || (f[0] != 'r' && f[0] != 'R') || (f[2] != 'c' && f[2] != 'C') ||
+			f[1] < '1' || f[1] > '9' || f[3] < '1' || f[3] > '9' {
+			return coord.Cage{}, fmt.Errorf("invalid cell %q, expected rXcY like r4c7", f)
+		}
+		cg.Cells = append(cg.Cells, coord.Itoc(int(f[1]-'1')*9+int(f[3]-'1')))
+	}
+	return cg, nil
+}
+
+// s is a killer puzzle, its second line is a cage
+func IsKiller(s string) bool {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) < 2 {
+		return false
+	}
+	_, err := parseCage(strings.TrimSpace(lines[1]))
+	return err == nil
+}
+
+// the lines of the cages of b in the killer format, nil if its layout has no cages
+func CageLines(b *board.Board) []string {
+	var r []string
+	for _, cg := range b.Layout().Cages() {
+		fs := []string{strconv.Itoa(cg.Sum)}
+		for _, c := range cg.Cells {
+			fs = append(fs, fmt.Sprintf("r%dc%d", c.Y+1, c.X+1))
+		}
+		r = append(r, strings.Join(fs, " "))
+	}
+	return r
+}
+
+// b in the killer format ParseKiller reads
+func KillerText(b *board.Board) string {
+	return strings.Join(append([]string{b.Line()}, CageLines(b)...), "\n")
+}
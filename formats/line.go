@@ -0,0 +1,18 @@
+// Reading and writing puzzles, collections and solving traces in the formats of other sudoku tools
+package formats
+
+import (
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+)
+
+// parses the 81 character line format, digits are givens and '0' or '.' are empty cells
+//
+// givens repeating a value in a house are a *board.InvalidPuzzleError
+func ParseLine(l coord.Layout, s string) (board.Board, error) {
+	b := board.New(l)
+	if err := b.UnmarshalText([]byte(s)); err != nil {
+		return board.Board{}, err
+	}
+	return b, nil
+}
@@ -0,0 +1,18 @@
+package formats
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/phaul/sudoku/solve"
+)
+
+// writes the steps of t as a markdown table
+func MarkdownTrace(w io.Writer, t solve.Trace) {
+	fmt.Fprintln(w, "| # | technique | cell | value |")
+	fmt.Fprintln(w, "|--:|---|---|:-:|")
+
+	for n, s := range t {
+		fmt.Fprintf(w, "| %d | %s | r%dc%d | %d |\n", n+1, s.Technique, s.Coord.Y+1, s.Coord.X+1, s.Value)
+	}
+}
@@ -0,0 +1,67 @@
+package formats
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+)
+
+// a puzzle collection in the OpenSudoku xml format
+type OpenSudoku struct {
+	XMLName     xml.Name         `xml:"opensudoku"`
+	Name        string           `xml:"name"`
+	Author      string           `xml:"author"`
+	Description string           `xml:"description"`
+	Comment     string           `xml:"comment"`
+	Created     string           `xml:"created"`
+	Source      string           `xml:"source"`
+	Level       string           `xml:"level"`
+	SourceURL   string           `xml:"sourceURL"`
+	Games       []OpenSudokuGame `xml:"game"`
+}
+
+// a puzzle of an OpenSudoku collection, data is the 81 character line format with '0' for empty cells
+type OpenSudokuGame struct {
+	Data string `xml:"data,attr"`
+}
+
+// writes c as an OpenSudoku xml document to w
+func (c OpenSudoku) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(c); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// the game of b
+func OpenSudokuOf(b *board.Board) OpenSudokuGame {
+	return OpenSudokuGame{Data: strings.ReplaceAll(b.Line(), ".", "0")}
+}
+
+// reads an OpenSudoku xml document, the attributes of the games other than the data, like those of the app exports, are
+// ignored
+func ReadOpenSudoku(r io.Reader) (OpenSudoku, error) {
+	c := OpenSudoku{}
+	if err := xml.NewDecoder(r).Decode(&c); err != nil {
+		return c, fmt.Errorf("opensudoku: %w", err)
+	}
+	return c, nil
+}
+
+// the metadata of the puzzles of c, the comment of the collection is taken as the license as a pack writes it
+func (c OpenSudoku) Meta() Metadata {
+	m := Metadata{Source: c.Source, Author: c.Author, License: c.Comment, Date: c.Created, Rating: c.Level}
+	if m.Source == "" {
+		m.Source = c.SourceURL
+	}
+	return m
+}
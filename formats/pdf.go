@@ -0,0 +1,139 @@
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+)
+
+// A4 page size and the grid placement in points
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	gridSize   = 450
+	gridLeft   = (pageWidth - gridSize) / 2
+	gridBottom = 220
+)
+
+// page margin and spacing of the answer key grids in points
+const (
+	keyMargin = 50
+	keyGap    = 20
+	keyLabel  = 14 // height of the label above a grid
+)
+
+// writes a printable pdf to w with a page per puzzle, each titled with its entry of titles
+//
+// the document is a minimal pdf 1.4 with uncompressed content streams, using only the built in Helvetica font
+func WritePDF(w io.Writer, titles []string, puzzles []board.Board) error {
+	return WritePDFWithKey(w, titles, puzzles, nil, 0)
+}
+
+// WritePDF followed by answer key pages, unless solutions is empty: the solution of every puzzle in a small grid,
+// perRow of them to a row, labeled with the number of the puzzle it solves
+func WritePDFWithKey(w io.Writer, titles []string, puzzles, solutions []board.Board, perRow int) error {
+	pages := []string{}
+	for i := range puzzles {
+		pages = append(pages, pdfPage(titles[i], &puzzles[i]))
+	}
+	if len(solutions) > 0 {
+		pages = append(pages, keyPages(solutions, max(perRow, 1))...)
+	}
+
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // pages, filled in once the page objects are numbered
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	kids := []string{}
+	for _, c := range pages {
+		objs = append(objs, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(c), c))
+		objs = append(objs, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, len(objs)))
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objs)))
+	}
+	objs[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	buf := bytes.Buffer{}
+	buf.WriteString("%PDF-1.4\n")
+	offsets := []int{}
+	for i, o := range objs {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// the content stream of a page with title and the grid of b
+func pdfPage(title string, b *board.Board) string {
+	c := strings.Builder{}
+
+	fmt.Fprintf(&c, "BT /F1 20 Tf %d %d Td (%s) Tj ET\n", gridLeft, gridBottom+gridSize+40, pdfEscape(title))
+	pdfGrid(&c, b, gridLeft, gridBottom, gridSize)
+	return c.String()
+}
+
+// the content streams of the answer key pages of solutions, perRow grids to a row
+func keyPages(solutions []board.Board, perRow int) []string {
+	size := (pageWidth - 2*keyMargin - float64(perRow-1)*keyGap) / float64(perRow)
+	top := float64(pageHeight - keyMargin - 40)
+	rows := max(int((top-keyMargin+keyGap)/(size+keyLabel+keyGap)), 1)
+
+	pages := []string{}
+	for first := 0; first < len(solutions); first += perRow * rows {
+		c := strings.Builder{}
+		fmt.Fprintf(&c, "BT /F1 20 Tf %d %d Td (Answers) Tj ET\n", keyMargin, pageHeight-keyMargin-20)
+		for i := first; i < min(first+perRow*rows, len(solutions)); i++ {
+			n := i - first
+			left := keyMargin + float64(n%perRow)*(size+keyGap)
+			bottom := top - float64(n/perRow+1)*(size+keyLabel+keyGap) + keyGap
+			fmt.Fprintf(&c, "BT /F1 10 Tf %.1f %.1f Td (%d) Tj ET\n", left, bottom+size+4, i+1)
+			pdfGrid(&c, &solutions[i], left, bottom, size)
+		}
+		pages = append(pages, c.String())
+	}
+	return pages
+}
+
+// draws the grid of b with its bottom left corner at left, bottom and sides of size
+func pdfGrid(c *strings.Builder, b *board.Board, left, bottom, size float64) {
+	cs := size / 9
+	font := cs * 0.52
+
+	for i := 0; i <= 9; i++ {
+		width := 0.5
+		if i%3 == 0 {
+			width = 2
+		}
+		width = max(width*size/gridSize, 0.25)
+		p := float64(i) * cs
+		fmt.Fprintf(c, "%.2f w %.1f %.1f m %.1f %.1f l S\n", width, left+p, bottom, left+p, bottom+size)
+		fmt.Fprintf(c, "%.2f w %.1f %.1f m %.1f %.1f l S\n", width, left, bottom+p, left+size, bottom+p)
+	}
+	for ix, v := range b.Values() {
+		if v == 0 {
+			continue
+		}
+		x := left + float64(ix%9)*cs + cs/2 - font*0.27
+		y := bottom + float64(8-ix/9)*cs + cs/2 - font*0.35
+		fmt.Fprintf(c, "BT /F1 %.1f Tf %.1f %.1f Td (%d) Tj ET\n", font, x, y, v)
+	}
+}
+
+// s as the contents of a pdf string literal
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
@@ -0,0 +1,40 @@
+package formats
+
+import (
+	"io"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+)
+
+// parses the simple sudoku .ss format, 9 rows with '|' between the boxes and a dashed line between the bands
+//
+// empty cells are '.' or 'X', the rest is as lenient as ParseGrid, of which the .ss format is a dialect
+func ParseSimpleSudoku(l coord.Layout, s string) (board.Board, error) {
+	return ParseGrid(l, strings.NewReplacer("X", ".", "x", ".").Replace(s))
+}
+
+// writes b to w in the simple sudoku .ss format, empty cells as '.'
+func WriteSimpleSudoku(w io.Writer, b *board.Board) error {
+	sb := strings.Builder{}
+	for ix, v := range b.Values() {
+		x, y := ix%9, ix/9
+		if x == 0 && y > 0 && y%3 == 0 {
+			sb.WriteString("-----------\n")
+		}
+		if x > 0 && x%3 == 0 {
+			sb.WriteByte('|')
+		}
+		if v == 0 {
+			sb.WriteByte('.')
+		} else {
+			sb.WriteByte('0' + byte(v))
+		}
+		if x == 8 {
+			sb.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,66 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// parses the 729 character sukaku format, the candidates of every cell in 9 characters: digit d as the d-th character
+// if it's a candidate, '0' or '.' otherwise
+//
+// a sukaku puzzle has no givens, the candidates are the clues. Blanks are ignored so the 81 cells can be spread over
+// lines or separated. A solver has to keep the candidates, with board.TrustMarks, instead of deriving them from the
+// values.
+func ParseSukaku(l coord.Layout, s string) (board.Board, error) {
+	s = strings.Join(strings.FieldsFunc(s, unicode.IsSpace), "")
+	if len(s) != 9*9*9 {
+		return board.Board{}, fmt.Errorf("sukaku has %d characters instead of 729", len(s))
+	}
+	ms := [9 * 9]uint16{}
+	for p, ch := range []byte(s) {
+		switch d := byte(p%9) + '1'; ch {
+		case '0', '.':
+		case d:
+			ms[p/9] |= 1 << (p % 9)
+		default:
+			return board.Board{}, fmt.Errorf("invalid character %q at %d, expected %c, '0' or '.'", ch, p+1, d)
+		}
+	}
+	return board.FromCandidates(l, ms), nil
+}
+
+// b in the sukaku format, a filled cell is written as its only candidate
+func SukakuLine(b *board.Board) string {
+	sb := strings.Builder{}
+	for ix := range 9 * 9 {
+		c := b.Cell(ix)
+		for d := cell.ValT(1); d <= 9; d++ {
+			if c.Value == d || (c.IsEmpty() && c.IsPossible(d)) {
+				sb.WriteByte('0' + byte(d))
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+	}
+	return sb.String()
+}
+
+// s is a sukaku, 729 digits or dots once the blanks are taken out
+func IsSukaku(s string) bool {
+	n := 0
+	for _, ch := range s {
+		switch {
+		case unicode.IsSpace(ch):
+		case ch == '.' || '0' <= ch && ch <= '9':
+			n++
+		default:
+			return false
+		}
+	}
+	return n == 9*9*9
+}
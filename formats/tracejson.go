@@ -0,0 +1,98 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/solve"
+)
+
+// version of the trace document, bumped on incompatible changes of schema/trace-v1.json
+const TraceSchema = "sudoku-trace/1"
+
+// a cell in a trace document, counting rows and columns from 1
+type TraceCell struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+// a candidate of a cell in a trace document
+type TraceCandidate struct {
+	TraceCell
+	Value cell.ValT `json:"value"`
+}
+
+// a link of a chain between two candidates, strong if one of them has to be true
+type TraceLink struct {
+	From   TraceCandidate `json:"from"`
+	To     TraceCandidate `json:"to"`
+	Strong bool           `json:"strong"`
+}
+
+// a step in a trace document
+type TraceStep struct {
+	Technique    string           `json:"technique"`
+	Cells        []TraceCell      `json:"cells"`        // cells the technique is based on
+	Placements   []TraceCandidate `json:"placements"`   // values placed by the step
+	Eliminations []TraceCandidate `json:"eliminations"` // candidates removed by the step
+	Links        []TraceLink      `json:"links"`        // chain of the step, empty for techniques without chains
+	Hash         string           `json:"hash"`         // hash of the board values after the step
+}
+
+// a solving trace as a versioned json document, independent of the internal types
+type TraceDocument struct {
+	Schema string      `json:"schema"`
+	Status string      `json:"status"`
+	Puzzle string      `json:"puzzle"` // the starting board in the 81 character line format
+	Hash   string      `json:"hash"`   // hash of the starting board values
+	Steps  []TraceStep `json:"steps"`
+}
+
+func traceCellOf(c coord.Coord) TraceCell { return TraceCell{Row: int(c.Y) + 1, Column: int(c.X) + 1} }
+
+// the trace document of solving b with outcome r
+func NewTraceDocument(b board.Board, r solve.Result) TraceDocument {
+	d := TraceDocument{Schema: TraceSchema, Status: r.Status.String(), Puzzle: b.Line(),
+		Hash: fmt.Sprintf("%016x", b.Hash()), Steps: []TraceStep{}}
+
+	for _, st := range r.Trace {
+		d.Steps = append(d.Steps, traceStep(&b, st))
+	}
+	return d
+}
+
+// the step st taken on b, as in a trace document
+func NewTraceStep(b board.Board, st solve.Step) TraceStep { return traceStep(&b, st) }
+
+// the step st as in a trace document, taking it on b
+func traceStep(b *board.Board, st solve.Step) TraceStep {
+	ts := TraceStep{
+		Technique:    st.Technique.String(),
+		Cells:        []TraceCell{traceCellOf(st.Coord)},
+		Placements:   []TraceCandidate{{traceCellOf(st.Coord), st.Value}},
+		Eliminations: []TraceCandidate{},
+		Links:        []TraceLink{},
+	}
+
+	ix := coord.Ctoi(st.Coord)
+	hit := b.Positions(st.Value).And(b.Layout().PeerSet(ix))
+	for p := hit.First(); p >= 0; p = hit.First() {
+		ts.Eliminations = append(ts.Eliminations, TraceCandidate{traceCellOf(coord.Itoc(p)), st.Value})
+		hit.Remove(p)
+	}
+
+	b.Fill(st.Coord, st.Value)
+	ts.Hash = fmt.Sprintf("%016x", b.Hash())
+	return ts
+}
+
+// writes the trace document of solving b with outcome r to w
+func WriteTraceJSON(w io.Writer, b board.Board, r solve.Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewTraceDocument(b, r))
+}
@@ -0,0 +1,145 @@
+package gen
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// a bank of distinct solution grids of the standard layout, in canonical form
+//
+// filling a grid is done once for the bank, generating from it only digs. Sampling puts a random equivalent of a grid
+// on the board, so puzzles from the bank are as varied as the grids up to equivalence.
+type Bank struct {
+	grids [][9 * 9]cell.ValT
+}
+
+// a bank of n random solution grids
+//
+// canonicalizing a grid is a search over its equivalents, so building a bank is far slower than filling its grids.
+// once ctx is done the grids so far are returned with the error of ctx.
+func NewBank(ctx context.Context, rng *rand.Rand, n int, s *Scratch) (*Bank, error) {
+	bk := &Bank{}
+	seen := map[[9 * 9]cell.ValT]bool{}
+	for len(bk.grids) < n {
+		b := board.New(coord.Standard)
+		if !randomFill(ctx, &b, rng, s, 0) {
+			return bk, ctx.Err()
+		}
+		g := b.Canonical()
+		if !seen[g] {
+			seen[g] = true
+			bk.grids = append(bk.grids, g)
+		}
+	}
+	return bk, nil
+}
+
+// reads a bank of solution grids in the 81 character line format, one per line
+//
+// the grids are taken as they are, not canonicalized again, blank lines and lines starting with '#' are skipped.
+func ReadBank(r io.Reader) (*Bank, error) {
+	bk := &Bank{}
+	s := bufio.NewScanner(r)
+	n := 0
+	for s.Scan() {
+		n++
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		g, err := parseGrid(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		bk.grids = append(bk.grids, g)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(bk.grids) == 0 {
+		return nil, fmt.Errorf("no solution grids in the bank")
+	}
+	return bk, nil
+}
+
+// the complete, valid standard grid in the line l
+func parseGrid(l string) ([9 * 9]cell.ValT, error) {
+	g := [9 * 9]cell.ValT{}
+	if len(l) != 9*9 {
+		return g, fmt.Errorf("grid has %d characters instead of 81", len(l))
+	}
+	for ix, ch := range []byte(l) {
+		if ch < '1' || ch > '9' {
+			return g, fmt.Errorf("invalid character %q at %d", ch, ix+1)
+		}
+		g[ix] = cell.ValT(ch - '0')
+	}
+	b := board.FromValues(coord.Standard, g)
+	if err := b.Validate(); err != nil {
+		return g, err
+	}
+	return g, nil
+}
+
+// writes the grids of bk to w, as ReadBank reads them
+func (bk *Bank) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, g := range bk.grids {
+		b := board.FromValues(coord.Standard, g)
+		fmt.Fprintln(bw, b.Line())
+	}
+	return bw.Flush()
+}
+
+// number of grids in bk
+func (bk *Bank) Len() int { return len(bk.grids) }
+
+// a random grid of bk with its rows and columns shuffled within and across bands and stacks, maybe transposed and its
+// digits relabeled
+func (bk *Bank) Sample(rng *rand.Rand) board.Board {
+	g := bk.grids[rng.Intn(len(bk.grids))]
+	ro, co := lineOrder(rng), lineOrder(rng)
+	t := rng.Intn(2) == 1
+	label := rng.Perm(9)
+
+	v := [9 * 9]cell.ValT{}
+	for ix := range v {
+		x, y := co[ix%9], ro[ix/9]
+		if t {
+			x, y = y, x
+		}
+		v[ix] = cell.ValT(label[g[y*9+x]-1] + 1)
+	}
+	return board.FromValues(coord.Standard, v)
+}
+
+// a random order of the 9 rows or columns keeping the bands together
+func lineOrder(rng *rand.Rand) [9]int {
+	o := [9]int{}
+	for i, band := range rng.Perm(3) {
+		for j, l := range rng.Perm(3) {
+			o[i*3+j] = band*3 + l
+		}
+	}
+	return o
+}
+
+// GenerateWith digging a solution sampled from bk instead of filling a random one, the puzzle is of the standard
+// layout
+func (bk *Bank) Generate(
+	ctx context.Context, rng *rand.Rand, s *Scratch, lim Limits,
+) (puzzle, solution board.Board, err error) {
+	solution = bk.Sample(rng)
+	if puzzle, err = dig(ctx, rng, solution, s, lim); err != nil {
+		return board.Board{}, board.Board{}, err
+	}
+	return puzzle, solution, nil
+}
@@ -0,0 +1,233 @@
+// Random puzzle generation for any layout, with a unique solution
+//
+// Generation is deterministic: the same rand.Rand state gives the same puzzle. The parallel variants, NewBankParallel
+// and GenerateRatedParallel, seed every task i with SubSeed(seed, i) and pick their result by i, so their output only
+// depends on the seed and never on the number of workers or how the goroutines are scheduled. It differs from the
+// output of the sequential functions given a rand.Rand of the same seed.
+package gen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/rate"
+	"github.com/phaul/sudoku/solve"
+)
+
+// counts the solutions of the board, giving up once limit is reached
+//
+// once ctx is done the solutions counted so far are returned
+func count(ctx context.Context, b board.Board, limit int) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+	solve.Singles(&b, nil)
+	if b.Solved() {
+		return 1
+	}
+	if b.Contradicts() {
+		return 0
+	}
+
+	c := b.Fewest()
+	i := b.At(c).Possibilities()
+	n := 0
+
+	for i.Next() && n < limit {
+		bb := b
+		bb.Fill(c, i.Value())
+		n += count(ctx, bb, limit-n)
+	}
+	return n
+}
+
+// reusable workspace of the generator
+//
+// a batch run passes the same scratch to every Generate call, so after the first few puzzles generation does no
+// heap allocation per puzzle. a scratch is not safe for concurrent use.
+type Scratch struct {
+	perm   [9 * 9]int          // order in which cells are dug out
+	boards [9 * 9]board.Board  // boards of randomFill, per depth of the guess
+	values [9 * 9][9]cell.ValT // shuffled candidates of the guessed cell, per depth of randomFill
+}
+
+// fills s.perm with a random permutation, the same one rng.Perm would return
+func (s *Scratch) permute(rng *rand.Rand) {
+	for i := range s.perm {
+		j := rng.Intn(i + 1)
+		s.perm[i] = s.perm[j]
+		s.perm[j] = i
+	}
+}
+
+// fills the board with a random solution
+//
+// returns false if there is no solution or ctx is done
+func randomFill(ctx context.Context, b *board.Board, rng *rand.Rand, s *Scratch, depth int) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	solve.Singles(b, nil)
+	if b.Solved() {
+		return true
+	}
+	if b.Contradicts() {
+		return false
+	}
+
+	c := b.Fewest()
+	vs := s.values[depth][:0]
+	for i := b.At(c).Possibilities(); i.Next(); {
+		vs = append(vs, i.Value())
+	}
+	rng.Shuffle(len(vs), func(i, j int) { vs[i], vs[j] = vs[j], vs[i] })
+
+	// every guess adds a value, so depth stays below the number of cells
+	bb := &s.boards[depth]
+	for _, v := range vs {
+		*bb = *b
+		bb.Fill(c, v)
+		if randomFill(ctx, bb, rng, s, depth+1) {
+			*b = *bb
+			return true
+		}
+	}
+	return false
+}
+
+// number of upcoming cells of the dig order the generator picks the next cell from
+const digWindow = 3
+
+// lower bounds on the clue distribution of generated puzzles, as counted by board.Analyze
+type Limits struct {
+	HouseClues int // clues in every house
+	DigitClues int // clues of every digit
+}
+
+// the clue at ix of v can be dug out without going under lim
+func (lim Limits) allow(l coord.Layout, v *[9 * 9]cell.ValT, ix int) bool {
+	if lim.DigitClues > 0 {
+		n := 0
+		for _, x := range v {
+			if x == v[ix] {
+				n++
+			}
+		}
+		if n <= lim.DigitClues {
+			return false
+		}
+	}
+	if lim.HouseClues > 0 {
+		for _, h := range l.HousesOf(ix) {
+			n := 0
+			for _, p := range l.HouseIndices()[h] {
+				if v[p] != 0 {
+					n++
+				}
+			}
+			if n <= lim.HouseClues {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// generates a puzzle with a unique solution in layout l, using s as workspace
+//
+// a random solution is dug out cell by cell as long as the solution stays unique. Out of the next few cells of a random
+// order the one leaving the loosest board is dug first, biasing towards puzzles that need more search.
+func Generate(ctx context.Context, rng *rand.Rand, l coord.Layout, s *Scratch) (puzzle, solution board.Board, err error) {
+	return GenerateWith(ctx, rng, l, s, Limits{})
+}
+
+// Generate keeping the clue distribution within lim, clues are only dug out while the limits allow it
+func GenerateWith(
+	ctx context.Context, rng *rand.Rand, l coord.Layout, s *Scratch, lim Limits,
+) (puzzle, solution board.Board, err error) {
+	solution = board.New(l)
+	if !randomFill(ctx, &solution, rng, s, 0) {
+		return board.Board{}, board.Board{}, ctx.Err()
+	}
+	if puzzle, err = dig(ctx, rng, solution, s, lim); err != nil {
+		return board.Board{}, board.Board{}, err
+	}
+	return puzzle, solution, nil
+}
+
+// digs out the clues of solution as GenerateWith does
+func dig(ctx context.Context, rng *rand.Rand, solution board.Board, s *Scratch, lim Limits) (board.Board, error) {
+	l := solution.Layout()
+	v := solution.Values()
+	s.permute(rng)
+	for i := range s.perm {
+		best, bt := i, 2.0
+		for j := i; j < min(i+digWindow, len(s.perm)); j++ {
+			val := v[s.perm[j]]
+			v[s.perm[j]] = 0
+			b := board.FromValues(l, v)
+			if t := b.Histogram().Tightness(); t < bt {
+				best, bt = j, t
+			}
+			v[s.perm[j]] = val
+		}
+		s.perm[i], s.perm[best] = s.perm[best], s.perm[i]
+
+		ix := s.perm[i]
+		if !lim.allow(l, &v, ix) {
+			continue
+		}
+		val := v[ix]
+		v[ix] = 0
+		if count(ctx, board.FromValues(l, v), 2) != 1 {
+			v[ix] = val
+		}
+		if ctx.Err() != nil {
+			return board.Board{}, ctx.Err()
+		}
+	}
+
+	return board.FromValues(l, v), nil
+}
+
+// no puzzle of the wanted difficulty came up in the tries given to GenerateRated
+var ErrDifficulty = errors.New("no puzzle of the difficulty generated")
+
+// calls next until it generates a puzzle of difficulty d, giving up with ErrDifficulty after tries puzzles
+//
+// next is Generate, GenerateWith or Bank.Generate bound to its arguments. The candidates are screened with
+// rate.Predict, only the ones predicted to be in band d are rated in full. The rating of the puzzle is returned with it.
+func GenerateRated(
+	ctx context.Context, d rate.Difficulty, tries int, next func() (puzzle, solution board.Board, err error),
+) (puzzle, solution board.Board, rt rate.Rating, err error) {
+	for range tries {
+		if puzzle, solution, err = next(); err != nil {
+			return board.Board{}, board.Board{}, rate.Rating{}, err
+		}
+		ok := false
+		if rt, ok, err = rated(ctx, d, &puzzle); err != nil {
+			return board.Board{}, board.Board{}, rate.Rating{}, err
+		}
+		if ok {
+			return puzzle, solution, rt, nil
+		}
+	}
+	return board.Board{}, board.Board{}, rate.Rating{}, fmt.Errorf("%w: %v in %d tries", ErrDifficulty, d, tries)
+}
+
+// the rating of puzzle and whether it's of difficulty d, the puzzles predicted to be in another band aren't rated
+func rated(ctx context.Context, d rate.Difficulty, puzzle *board.Board) (rate.Rating, bool, error) {
+	if rate.Predict(puzzle).Difficulty != min(d, rate.Hard) {
+		return rate.Rating{}, false, nil
+	}
+	rt, err := rate.Rate(ctx, puzzle)
+	if err != nil {
+		return rate.Rating{}, false, err
+	}
+	return rt, rt.Status == solve.Solved && rt.Difficulty == d, nil
+}
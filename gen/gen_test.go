@@ -0,0 +1,95 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/solve"
+)
+
+// checks that puzzle is a unique solution puzzle of layout l with solution, and that solution keeps the rules of l
+func checkPuzzle(t *testing.T, l coord.Layout, puzzle, solution board.Board) {
+	t.Helper()
+	pv, sv := puzzle.Values(), solution.Values()
+	for ix, v := range sv {
+		switch {
+		case v == 0:
+			t.Fatalf("%s: the solution has an empty cell", solution.Line())
+		case pv[ix] != 0 && pv[ix] != v:
+			t.Fatalf("%s: clue %d at %d isn't the %d of the solution", puzzle.Line(), pv[ix], ix, v)
+		}
+		for _, p := range l.PeerIndices(ix) {
+			if sv[p] == v {
+				t.Fatalf("%s: %d repeats at %d and %d", solution.Line(), v, ix, p)
+			}
+		}
+	}
+
+	b := board.FromValues(l, pv)
+	r, err := solve.Compact{Limit: 2}.Solve(context.Background(), &b)
+	if err != nil || r.Status != solve.Solved || r.Solution.Values() != sv {
+		t.Fatalf("%s: %v %v %s", puzzle.Line(), r.Status, err, r.Solution.Line())
+	}
+}
+
+func TestGenerateVariants(t *testing.T) {
+	for name, l := range map[string]coord.Layout{
+		"standard":    coord.Standard,
+		"x":           coord.X,
+		"windoku":     coord.Windoku,
+		"anti-knight": coord.AntiKnight,
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := &Scratch{}
+			for seed := range int64(5) {
+				p, sol, err := Generate(context.Background(), rand.New(rand.NewSource(seed)), l, s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkPuzzle(t, l, p, sol)
+			}
+		})
+	}
+}
+
+func TestGenerateKiller(t *testing.T) {
+	s := &Scratch{}
+	for seed := range int64(5) {
+		p, sol, err := GenerateKiller(context.Background(), rand.New(rand.NewSource(seed)), s, CageSizes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := sol.Layout()
+		if len(l.Cages()) == 0 || p.Layout().Cages() == nil {
+			t.Fatalf("%s: no cages", p.Line())
+		}
+		covered := 0
+		for k, cg := range l.Cages() {
+			covered += len(cg.Cells)
+			sum := 0
+			for _, ix := range l.CageIndices(k) {
+				sum += int(sol.Cell(ix).Value)
+			}
+			if sum != cg.Sum || len(cg.Cells) > len(CageSizes) {
+				t.Fatalf("%s: cage %d of %d cells adds up to %d, not %d", sol.Line(), k+1, len(cg.Cells), sum, cg.Sum)
+			}
+		}
+		if covered != 9*9 {
+			t.Fatalf("%s: cages cover %d cells", sol.Line(), covered)
+		}
+		checkPuzzle(t, l, p, sol)
+	}
+}
+
+func TestGenerateKillerSizes(t *testing.T) {
+	for _, sizes := range [][]int{nil, {0, 0}, {1, -1}, make([]int, 10)} {
+		_, _, err := GenerateKiller(context.Background(), rand.New(rand.NewSource(1)), &Scratch{}, sizes)
+		if !errors.Is(err, ErrCageSizes) {
+			t.Errorf("%v: %v", sizes, err)
+		}
+	}
+}
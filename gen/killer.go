@@ -0,0 +1,100 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// weights of the cage sizes of generated killer puzzles, a cage of i+1 cells has weight CageSizes[i]
+var CageSizes = []int{1, 8, 8, 4, 2}
+
+// the weights of cage sizes can't be drawn from
+var ErrCageSizes = errors.New("cage sizes need 1 to 9 weights, none negative and not all 0")
+
+// generates a killer puzzle with a unique solution, using s as workspace
+//
+// a random solution of the classic layout is covered with cages, grown from random cells over their unassigned
+// orthogonal neighbours not repeating a digit of the cage, to sizes drawn with the weights sizes, as in CageSizes. The
+// clues are then dug out as Generate digs them, in the layout of the cages, so most puzzles come out with no givens.
+func GenerateKiller(
+	ctx context.Context, rng *rand.Rand, s *Scratch, sizes []int,
+) (puzzle, solution board.Board, err error) {
+	total := 0
+	for _, w := range sizes {
+		if w < 0 {
+			return board.Board{}, board.Board{}, ErrCageSizes
+		}
+		total += w
+	}
+	if len(sizes) > 9 || total == 0 {
+		return board.Board{}, board.Board{}, ErrCageSizes
+	}
+
+	solution = board.New(coord.Standard)
+	if !randomFill(ctx, &solution, rng, s, 0) {
+		return board.Board{}, board.Board{}, ctx.Err()
+	}
+	v := solution.Values()
+	l, err := coord.Killer(randomCages(rng, &v, sizes, total)...)
+	if err != nil {
+		return board.Board{}, board.Board{}, err
+	}
+	solution = board.FromValues(l, v)
+	if puzzle, err = dig(ctx, rng, solution, s, Limits{}); err != nil {
+		return board.Board{}, board.Board{}, err
+	}
+	return puzzle, solution, nil
+}
+
+// cages covering the solution v, of sizes drawn with the weights sizes adding up to total
+//
+// a cage stops short of its size when none of its neighbours can join it.
+func randomCages(rng *rand.Rand, v *[9 * 9]cell.ValT, sizes []int, total int) []coord.Cage {
+	taken := coord.Set{}
+	cages := []coord.Cage{}
+	for _, start := range rng.Perm(9 * 9) {
+		if taken.Has(start) {
+			continue
+		}
+		size := 1
+		for w := rng.Intn(total); w >= sizes[size-1]; size++ {
+			w -= sizes[size-1]
+		}
+
+		ixs := []int{start}
+		taken.Add(start)
+		used := 1 << v[start]
+		for len(ixs) < size {
+			next := []int{}
+			for _, ix := range ixs {
+				for _, p := range [4]int{ix - 9, ix + 9, ix - 1, ix + 1} {
+					// the cells left and right of ix are on its row
+					ok := 0 <= p && p < 9*9 && (p/9 == ix/9 || p%9 == ix%9)
+					if ok && !taken.Has(p) && used&(1<<v[p]) == 0 {
+						next = append(next, p)
+					}
+				}
+			}
+			if len(next) == 0 {
+				break
+			}
+			p := next[rng.Intn(len(next))]
+			ixs = append(ixs, p)
+			taken.Add(p)
+			used |= 1 << v[p]
+		}
+
+		cg := coord.Cage{}
+		for _, ix := range ixs {
+			cg.Cells = append(cg.Cells, coord.Itoc(ix))
+			cg.Sum += int(v[ix])
+		}
+		cages = append(cages, cg)
+	}
+	return cages
+}
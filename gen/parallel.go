@@ -0,0 +1,129 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/rate"
+)
+
+// the seed of the i-th task of a parallel generation seeded with seed
+//
+// the seeds are mixed with splitmix64, so neighbouring tasks and neighbouring seeds get unrelated random sequences.
+func SubSeed(seed int64, i int) int64 {
+	z := uint64(seed) + uint64(i+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
+
+// runs task for i = from, from+1, ... on workers goroutines, each with its own scratch, until task returns true for
+// some i or i reaches to
+//
+// task i is given a rand.Rand seeded with SubSeed(seed, i). Tasks are started in the order of i and none is started
+// after one returning true, the ones already started are finished. Returns the smallest i task returned true for, all
+// the tasks before it have run, or to if there is none.
+func parallel(workers int, seed int64, from, to int, task func(i int, rng *rand.Rand, s *Scratch) bool) int {
+	mu := sync.Mutex{}
+	next, first := from, to
+	wg := sync.WaitGroup{}
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := &Scratch{}
+			for {
+				mu.Lock()
+				i, done := next, next >= first
+				next++
+				mu.Unlock()
+				if done {
+					return
+				}
+				if task(i, rand.New(rand.NewSource(SubSeed(seed, i))), s) {
+					mu.Lock()
+					first = min(first, i)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return first
+}
+
+// NewBank on workers goroutines, grid i is filled from a rand.Rand seeded with SubSeed(seed, i)
+//
+// the grids are taken in the order of i skipping the ones equivalent to an earlier grid, so the bank only depends on
+// seed and n, never on workers. once ctx is done the grids so far are returned with the error of ctx.
+func NewBankParallel(ctx context.Context, seed int64, n, workers int) (*Bank, error) {
+	bk := &Bank{}
+	seen := map[[9 * 9]cell.ValT]bool{}
+	for from := 0; len(bk.grids) < n; {
+		to := from + n - len(bk.grids)
+		grids := make([][9 * 9]cell.ValT, to-from)
+		ok := make([]bool, to-from)
+		parallel(workers, seed, from, to, func(i int, rng *rand.Rand, s *Scratch) bool {
+			b := board.New(coord.Standard)
+			if ok[i-from] = randomFill(ctx, &b, rng, s, 0); ok[i-from] {
+				grids[i-from] = b.Canonical()
+			}
+			return false
+		})
+		for i, g := range grids {
+			if !ok[i] {
+				return bk, ctx.Err()
+			}
+			if !seen[g] {
+				seen[g] = true
+				bk.grids = append(bk.grids, g)
+			}
+		}
+		from = to
+	}
+	return bk, nil
+}
+
+// GenerateRated on workers goroutines, try i calling next with a rand.Rand seeded with SubSeed(seed, i) and the
+// scratch of its goroutine
+//
+// the puzzle returned is the one of the first try in the order of i that makes difficulty d, so it only depends on
+// seed, d and next, never on workers or the scheduling of the goroutines.
+func GenerateRatedParallel(
+	ctx context.Context, d rate.Difficulty, tries, workers int, seed int64,
+	next func(rng *rand.Rand, s *Scratch) (puzzle, solution board.Board, err error),
+) (puzzle, solution board.Board, rt rate.Rating, err error) {
+	type result struct {
+		puzzle, solution board.Board
+		rt               rate.Rating
+		err              error
+	}
+	mu := sync.Mutex{}
+	rs := map[int]result{}
+	i := parallel(workers, seed, 0, tries, func(i int, rng *rand.Rand, s *Scratch) bool {
+		r := result{}
+		if r.puzzle, r.solution, r.err = next(rng, s); r.err == nil {
+			var ok bool
+			if r.rt, ok, r.err = rated(ctx, d, &r.puzzle); r.err == nil && !ok {
+				return false
+			}
+		}
+		mu.Lock()
+		rs[i] = r
+		mu.Unlock()
+		return true
+	})
+	if i == tries {
+		return board.Board{}, board.Board{}, rate.Rating{}, fmt.Errorf("%w: %v in %d tries", ErrDifficulty, d, tries)
+	}
+	r := rs[i]
+	if r.err != nil {
+		return board.Board{}, board.Board{}, rate.Rating{}, r.err
+	}
+	return r.puzzle, r.solution, r.rt, nil
+}
@@ -0,0 +1,3 @@
+module github.com/phaul/sudoku
+
+go 1.23
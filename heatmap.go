@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"github.com/phaul/sudoku/solve"
+)
+
+// where the logic solver guessed and which digits it took back over a batch
+type heatmap struct {
+	guesses    [9 * 9]int // by cell index
+	backtracks [9]int     // by digit, 1 first
+}
+
+// adds the guesses and backtracks of a solve
+func (h *heatmap) add(s solve.Stats) {
+	for ix, n := range s.Guesses {
+		h.guesses[ix] += n
+	}
+	for d, n := range s.Backtracks {
+		h.backtracks[d] += n
+	}
+}
+
+// writes h to fn, in the format picked by its extension, .csv or .png
+func (h *heatmap) write(fn string) error {
+	switch filepath.Ext(fn) {
+	case ".csv":
+		return writeFile(fn, h.writeCSV)
+	case ".png":
+		return writeFile(fn, h.writePNG)
+	}
+	return fmt.Errorf("unknown heatmap format %q", fn)
+}
+
+// a row per cell with its guesses, then a row per digit with its backtracks
+func (h *heatmap) writeCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"kind", "row", "column", "digit", "count"})
+	for ix, n := range h.guesses {
+		c.Write([]string{"guess", strconv.Itoa(ix/9 + 1), strconv.Itoa(ix%9 + 1), "", strconv.Itoa(n)})
+	}
+	for d, n := range h.backtracks {
+		c.Write([]string{"backtrack", "", "", strconv.Itoa(d + 1), strconv.Itoa(n)})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// side of a heatmap square in pixels
+const heatCell = 40
+
+// the grid of guesses, with the backtracks of the digits 1 to 9 in a strip under it, shaded from white for none to red
+// for the most
+func (h *heatmap) writePNG(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, 9*heatCell, 10*heatCell+heatCell/2))
+	for p := range img.Pix {
+		img.Pix[p] = 0xff
+	}
+
+	top := 0
+	for _, n := range h.guesses {
+		top = max(top, n)
+	}
+	for ix, n := range h.guesses {
+		heatSquare(img, ix%9*heatCell, ix/9*heatCell, n, top, ix%9%3 == 0, ix/9%3 == 0)
+	}
+
+	top = 0
+	for _, n := range h.backtracks {
+		top = max(top, n)
+	}
+	for d, n := range h.backtracks {
+		heatSquare(img, d*heatCell, 9*heatCell+heatCell/2, n, top, true, true)
+	}
+	return png.Encode(w, img)
+}
+
+// shades the square at x, y by n out of top, with a dark edge on the left and top sides when they start a box
+func heatSquare(img *image.RGBA, x, y, n, top int, left, above bool) {
+	c := uint8(0xff)
+	if top > 0 {
+		c = uint8(0xff - 0xff*n/top)
+	}
+	for i := range heatCell {
+		for j := range heatCell {
+			px := color.RGBA{0xff, c, c, 0xff}
+			switch {
+			case (i == 0 && left) || (j == 0 && above):
+				px = color.RGBA{0, 0, 0, 0xff}
+			case i == 0 || j == 0 || i == heatCell-1 || j == heatCell-1:
+				px = color.RGBA{0x80, 0x80, 0x80, 0xff}
+			}
+			img.SetRGBA(x+i, y+j, px)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/rate"
+)
+
+// output formats of -output, the modes writing a line per puzzle only have text and jsonl
+var outputs = []string{"text", "jsonl", "json", "hodoku", "pdf"}
+
+// the outcome of a puzzle of -batch, -rate, -audit or -generate in the jsonl output
+type jsonResult struct {
+	File     string       `json:"file,omitempty"`
+	Line     int          `json:"line,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Puzzle   string       `json:"puzzle"`
+	Cages    []string     `json:"cages,omitempty"`    // of a killer puzzle, a sum and cells each as in the killer format
+	Status   string       `json:"status,omitempty"`   // outcome of the solve or the audit
+	Solution string       `json:"solution,omitempty"` // in the 81 character line format
+	Rating   *rate.Rating `json:"rating,omitempty"`
+	Error    string       `json:"error,omitempty"` // why the puzzle is invalid
+}
+
+// the result of the puzzle of e, without its outcome
+func jsonResultOf(e formats.Entry) jsonResult {
+	return jsonResult{File: e.File, Line: e.Line, ID: e.ID, Puzzle: e.Puzzle}
+}
+
+// writes a json object per line as soon as it's given, from any number of goroutines
+//
+// a nil *jsonLines is the text output, the commands check for it before building their results
+type jsonLines struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error // the first write error, the writes after it are dropped
+}
+
+// the jsonl writer of -output o to w, nil for the text output
+func newJSONLines(o string, w io.Writer) *jsonLines {
+	if o != "jsonl" {
+		return nil
+	}
+	return &jsonLines{enc: json.NewEncoder(w)}
+}
+
+// writes r on a line of its own
+func (j *jsonLines) write(r jsonResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err == nil {
+		j.err = j.enc.Encode(r)
+	}
+}
+
+// the first write error
+func (j *jsonLines) error() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/phaul/sudoku/gen"
+)
+
+// the -cage-sizes flag, weights of the sizes of the cages of a killer -generate
+type cageSizes []int
+
+func (s *cageSizes) String() string {
+	ws := []string{}
+	for _, w := range *s {
+		ws = append(ws, strconv.Itoa(w))
+	}
+	return strings.Join(ws, ",")
+}
+
+func (s *cageSizes) Set(v string) error {
+	ws := cageSizes{}
+	total := 0
+	for _, f := range strings.Split(v, ",") {
+		w, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || w < 0 {
+			return fmt.Errorf("invalid weight %q", f)
+		}
+		ws = append(ws, w)
+		total += w
+	}
+	if len(ws) > 9 || total == 0 {
+		return gen.ErrCageSizes
+	}
+	*s = ws
+	return nil
+}
+
+// prints the lines ls to the standard output, a line each
+func printLines(ls []string) {
+	for _, l := range ls {
+		fmt.Println(l)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// a number of bytes given on the command line, with an optional K, M or G suffix for KiB, MiB or GiB
+type byteSize uint64
+
+func (s *byteSize) String() string { return fmt.Sprint(uint64(*s)) }
+
+func (s *byteSize) Set(v string) error {
+	mul := uint64(1)
+	switch {
+	case strings.HasSuffix(v, "K"):
+		mul = 1 << 10
+	case strings.HasSuffix(v, "M"):
+		mul = 1 << 20
+	case strings.HasSuffix(v, "G"):
+		mul = 1 << 30
+	}
+	if mul != 1 {
+		v = v[:len(v)-1]
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q", v)
+	}
+	*s = byteSize(n * mul)
+	return nil
+}
+
+// human readable form of n bytes
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// how often the heap is sampled
+const memoryInterval = 10 * time.Millisecond
+
+// samples the heap in the background, recording its peak and enforcing a limit
+type memoryGuard struct {
+	peak atomic.Uint64
+	done chan struct{}
+}
+
+// starts watching the heap, the returned ctx is cancelled with errMemory once the heap grows over limit bytes
+//
+// limit 0 only records the peak. stop has to be called to end the watch.
+func watchMemory(ctx context.Context, limit uint64) (context.Context, *memoryGuard) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &memoryGuard{done: make(chan struct{})}
+	g.sample()
+
+	go func() {
+		t := time.NewTicker(memoryInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-g.done:
+				cancel(nil)
+				return
+			case <-t.C:
+				if n := g.sample(); limit > 0 && n > limit {
+					cancel(fmt.Errorf("%w: heap reached %s", errMemory, formatBytes(n)))
+				}
+			}
+		}
+	}()
+	return ctx, g
+}
+
+// reads the heap size, updating the peak
+func (g *memoryGuard) sample() uint64 {
+	s := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(s)
+	n := s[0].Value.Uint64()
+	for p := g.peak.Load(); n > p && !g.peak.CompareAndSwap(p, n); p = g.peak.Load() {
+	}
+	return n
+}
+
+// ends the watch, returning the peak heap size in bytes
+func (g *memoryGuard) stop() uint64 {
+	g.sample()
+	close(g.done)
+	return g.peak.Load()
+}
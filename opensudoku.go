@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/formats"
+)
+
+// writes the puzzles of the sdm, puzzle bank or OpenSudoku files fns to fn as an OpenSudoku collection named after fn,
+// with the metadata all the puzzles share
+func openSudokuMain(fn string, fns []string) error {
+	c := formats.OpenSudoku{
+		Name:    strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn)),
+		Created: time.Now().Format(time.DateOnly),
+	}
+	meta := formats.Metadata{}
+	for _, in := range fns {
+		err := readPuzzles(in, func(e formats.Entry, b board.Board, err error) error {
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", e.File, e.Line, err)
+			}
+			if len(c.Games) == 0 {
+				meta = e.Meta
+			}
+			meta = meta.Common(e.Meta)
+			c.Games = append(c.Games, formats.OpenSudokuOf(&b))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(c.Games) == 0 {
+		return fmt.Errorf("no puzzles to write to %s", fn)
+	}
+	c.Author, c.Source, c.Comment, c.Level = meta.Author, meta.Source, meta.License, meta.Rating
+	c.Description = fmt.Sprintf("%d puzzles", len(c.Games))
+	if meta.Date != "" {
+		c.Created = meta.Date
+	}
+	return writeFile(fn, c.Write)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cache"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/puzzles"
+	"github.com/phaul/sudoku/rate"
+	"github.com/phaul/sudoku/solve"
+)
+
+// number of puzzles of each difficulty band in a pack, easiest band first
+type progression [rate.Diabolical + 1]int
+
+// parses the comma separated puzzle counts of the bands, as in 20,20,10, the counts left off are 0
+func parseProgression(s string) (progression, error) {
+	p := progression{}
+	fs := strings.Split(s, ",")
+	if len(fs) < len(p)-1 || len(fs) > len(p) {
+		return p, fmt.Errorf("progression %q has %d counts instead of %d or %d", s, len(fs), len(p)-1, len(p))
+	}
+	for i, f := range fs {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid count %q in progression", f)
+		}
+		p[i] = n
+	}
+	return p, nil
+}
+
+// symmetry filter values: any symmetry or a single one
+func symmetryNames() []string {
+	ns := []string{"any"}
+	for _, s := range board.AllSymmetries {
+		ns = append(ns, s.String())
+	}
+	return ns
+}
+
+// filter keeping the puzzles with the symmetry named s, as in symmetryNames, every puzzle for ""
+func symmetryFilter(s string) (func(*board.Board) bool, error) {
+	switch s {
+	case "":
+		return func(*board.Board) bool { return true }, nil
+	case "any":
+		return func(b *board.Board) bool { return b.Symmetry() != board.NoSymmetry }, nil
+	}
+	sym, err := board.ParseSymmetry(s)
+	if err != nil {
+		return nil, err
+	}
+	return func(b *board.Board) bool { return b.Symmetry()&sym != 0 }, nil
+}
+
+// picks the puzzles of a pack following p out of pool, least demanding first
+//
+// puzzles without a unique solution are left out, and so are puzzles whose solution grid is equivalent to the solution
+// of a puzzle already picked, which takes care of equivalent puzzles too. The puzzles of the pool sharing the same
+// solution grid are reported to log, a cluster per line. The puzzles are in the order of their ratings, so difficulty
+// never drops along the pack. Ratings are taken from rc when the rater hasn't changed since, the new ratings are stored
+// in it.
+func buildPack(ctx context.Context, log io.Writer, pool []formats.Entry, p progression, rc ratingCache) ([]rated, error) {
+	bands := [rate.Diabolical + 1][]rated{}
+	fp := rate.Fingerprint()
+	solutions := map[string]board.Board{}
+	clusters := map[[9 * 9]cell.ValT][]formats.Entry{}
+	grids := [][9 * 9]cell.ValT{} // solution grids in the order of the pool
+	for _, e := range pool {
+		b, err := formats.ParseLine(coord.Standard, e.Puzzle)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", e.File, e.Line, err)
+		}
+		key := b.Line()
+		rt, ok := rc.get(key, fp)
+		if !ok {
+			if rt, err = rate.Rate(ctx, &b); err != nil {
+				return nil, err
+			}
+			rc.put(key, fp, rt)
+		}
+		if rt.Status != solve.Solved {
+			continue
+		}
+		bands[rt.Difficulty] = append(bands[rt.Difficulty], rated{Entry: e, rating: rt})
+		if _, ok := solutions[key]; !ok {
+			r, err := solve.DLX{Limit: 1}.Solve(ctx, &b)
+			if err != nil {
+				return nil, err
+			}
+			solutions[key] = r.Solution
+		}
+		sol := solutions[key]
+		g := sol.Values()
+		if _, ok := clusters[g]; !ok {
+			grids = append(grids, g)
+		}
+		clusters[g] = append(clusters[g], e)
+	}
+	for _, g := range grids {
+		if es := clusters[g]; len(es) > 1 {
+			fmt.Fprintf(log, "%d puzzles share a solution grid:", len(es))
+			for _, e := range es {
+				fmt.Fprintf(log, " %s:%d", e.File, e.Line)
+			}
+			fmt.Fprintln(log)
+		}
+	}
+
+	// canonical forms of the solutions taken, to leave out puzzles with a solution equivalent to one already in the pack
+	taken := cache.New[[9 * 9]cell.ValT, struct{}](len(pool), 0)
+	pack := []rated{}
+	// the score of a rating is its difficulty plus a fraction, so taking the bands in order, each sorted by rating,
+	// makes the difficulty of the pack never go down
+	for d, rs := range bands {
+		slices.SortStableFunc(rs, func(a, b rated) int { return a.rating.Compare(b.rating) })
+		n := 0
+		for _, r := range rs {
+			if n == p[d] {
+				break
+			}
+			b, _ := formats.ParseLine(coord.Standard, r.Puzzle)
+			sol := solutions[b.Line()]
+			k := sol.Canonical()
+			if _, ok := taken.Get(k); ok {
+				continue
+			}
+			taken.Put(k, struct{}{})
+			pack = append(pack, r)
+			n++
+		}
+		if n < p[d] {
+			return nil, fmt.Errorf("%d %v puzzles needed, only %d distinct ones available", p[d], rate.Difficulty(d), n)
+		}
+	}
+	return pack, nil
+}
+
+// number of solution grids in a row of the answer key of a pack
+const keyPerRow = 4
+
+// builds a pack following p from the puzzles of files, or the built in samples if there are no files, that keep accepts,
+// writing name.sdm
+// with the metadata of the puzzles, name.xml in the OpenSudoku format with the metadata all puzzles share and the
+// printable name.pdf, with the answer key at the end
+//
+// the ratings of the pool are kept in name.ratings, a later build of the same name only rates the puzzles that weren't
+// rated by the same rater before
+func packMain(
+	ctx context.Context, log io.Writer, name string, p progression, keep func(*board.Board) bool, files []string,
+) error {
+	pool := []formats.Entry{}
+	for _, fn := range files {
+		err := readPuzzles(fn, func(e formats.Entry, b board.Board, err error) error {
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", e.File, e.Line, err)
+			}
+			if keep(&b) {
+				pool = append(pool, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(files) == 0 {
+		for d := puzzles.Easy; d <= puzzles.Hard; d++ {
+			for i, l := range puzzles.Samples(d) {
+				if b, _ := formats.ParseLine(coord.Standard, l); keep(&b) {
+					pool = append(pool, formats.Entry{File: "samples/" + d.String(), Line: i + 1, Puzzle: l})
+				}
+			}
+		}
+	}
+
+	rc, err := loadRatings(name + ".ratings")
+	if err != nil {
+		return err
+	}
+	// the ratings of the puzzles that left the pool are dropped
+	kept := ratingCache{Ratings: map[string]cachedRating{}}
+	for _, e := range pool {
+		if b, err := formats.ParseLine(coord.Standard, e.Puzzle); err == nil {
+			if cr, ok := rc.Ratings[b.Line()]; ok {
+				kept.Ratings[b.Line()] = cr
+			}
+		}
+	}
+	// the ratings are worth keeping even if the pool can't fill the progression
+	pack, err := buildPack(ctx, log, pool, p, kept)
+	if err := writeFile(name+".ratings", kept.write); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if len(pack) == 0 {
+		return fmt.Errorf("empty progression")
+	}
+
+	meta := pack[0].Meta
+	es := []formats.Entry{}
+	for _, r := range pack {
+		meta = meta.Common(r.Meta)
+		es = append(es, r.Entry)
+	}
+
+	c := formats.OpenSudoku{
+		Name:    filepath.Base(name),
+		Author:  meta.Author,
+		Source:  meta.Source,
+		Comment: meta.License,
+		Description: fmt.Sprintf("%d easy, %d medium, %d hard and %d diabolical puzzles", p[rate.Easy], p[rate.Medium],
+			p[rate.Hard], p[rate.Diabolical]),
+		Created: time.Now().Format(time.DateOnly),
+		Level:   fmt.Sprintf("%v to %v", pack[0].rating.Difficulty, pack[len(pack)-1].rating.Difficulty),
+	}
+	titles := []string{}
+	boards := []board.Board{}
+	solutions := []board.Board{}
+	for i, r := range pack {
+		b, _ := formats.ParseLine(coord.Standard, r.Puzzle)
+		c.Games = append(c.Games, formats.OpenSudokuOf(&b))
+		titles = append(titles, fmt.Sprintf("%s %d/%d - %v", filepath.Base(name), i+1, len(pack), r.rating.Difficulty))
+		boards = append(boards, b)
+		s, err := solve.DLX{Limit: 1}.Solve(ctx, &b)
+		if err != nil {
+			return err
+		}
+		solutions = append(solutions, s.Solution)
+	}
+
+	if err := writeFile(name+".sdm", func(w io.Writer) error { return formats.WriteCollection(w, es) }); err != nil {
+		return err
+	}
+	if err := writeFile(name+".xml", c.Write); err != nil {
+		return err
+	}
+	return writeFile(name+".pdf", func(w io.Writer) error {
+		return formats.WritePDFWithKey(w, titles, boards, solutions, keyPerRow)
+	})
+}
+
+// formats.ReadPuzzles, reading standard input for fn "-"
+func readPuzzles(fn string, f func(e formats.Entry, b board.Board, err error) error) error {
+	if fn == "-" {
+		return formats.ReadPuzzlesFrom("stdin", os.Stdin, f)
+	}
+	return formats.ReadPuzzles(fn, f)
+}
+
+// creates fn and writes it with f
+func writeFile(fn string, f func(io.Writer) error) error {
+	w, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+	if err := f(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
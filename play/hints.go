@@ -0,0 +1,86 @@
+package play
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/solve"
+)
+
+// a rung of a hint ladder, each rung gives away more of the next step than the one before
+type HintLevel int
+
+const (
+	HintHouse     HintLevel = iota + 1 // the house to look at
+	HintFocus                          // the digit, or for a single candidate the cell to look for in the house
+	HintPlacement                      // the placement and the reasoning behind it
+)
+
+// escalating hints for the next step from the position played so far, the first n rungs of the ladder
+//
+// the rungs are about the same step, so an app can reveal them one at a time. n over HintPlacement gives the whole
+// ladder.
+func (s *Session) Hints(ctx context.Context, n int) ([]string, error) {
+	if n < int(HintHouse) {
+		return nil, fmt.Errorf("hint ladder of %d rungs", n)
+	}
+	n = min(n, int(HintPlacement))
+
+	b, st, err := s.next(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l := b.Layout()
+	h := house(&b, st)
+	at := fmt.Sprintf("r%dc%d", st.Coord.Y+1, st.Coord.X+1)
+	ladder := []string{fmt.Sprintf("look at %s", l.HouseName(h))}
+	switch st.Technique {
+	case solve.HiddenSingle:
+		ladder = append(ladder,
+			fmt.Sprintf("digit %d in %s", st.Value, l.HouseName(h)),
+			fmt.Sprintf("%d goes at %s, it's the only place for %d in %s", st.Value, at, st.Value, l.HouseName(h)))
+	case solve.NakedSingle:
+		ladder = append(ladder,
+			fmt.Sprintf("cell %s in %s", at, l.HouseName(h)),
+			fmt.Sprintf("%d goes at %s, every other digit is placed in a house of the cell", st.Value, at))
+	default:
+		ladder = append(ladder,
+			fmt.Sprintf("no singles left, guess cell %s in %s", at, l.HouseName(h)),
+			fmt.Sprintf("%d goes at %s, try it and follow the singles", st.Value, at))
+	}
+
+	m := moveAt(MoveHint, st.Coord, st.Value)
+	m.Level = HintLevel(n)
+	s.record(m, false, board.Board{})
+	return ladder[:n], nil
+}
+
+// the house the step st on b is found in
+//
+// for a hidden single it's the house the solver found it in, otherwise it's the house of the cell with the fewest
+// empty cells
+func house(b *board.Board, st solve.Step) int {
+	if st.House.Kind != "" {
+		return st.House.ID
+	}
+	l := b.Layout()
+	ix := coord.Ctoi(st.Coord)
+	r := l.HousesOf(ix)[0]
+	empty := 10
+
+	for _, h := range l.HousesOf(ix) {
+		n := 0
+		for _, p := range l.HouseIndices()[h] {
+			if b.Cell(p).IsEmpty() {
+				n++
+			}
+		}
+		if n < empty {
+			r, empty = h, n
+		}
+	}
+	return r
+}
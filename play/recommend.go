@@ -0,0 +1,37 @@
+package play
+
+import (
+	"time"
+
+	"github.com/phaul/sudoku/rate"
+)
+
+// time a player at home in a difficulty takes for a puzzle of it, the yardstick of Stats.Pace
+var ExpectedTime = [rate.Diabolical + 1]time.Duration{
+	rate.Easy:       5 * time.Minute,
+	rate.Medium:     10 * time.Minute,
+	rate.Hard:       20 * time.Minute,
+	rate.Diabolical: 40 * time.Minute,
+}
+
+// recommends the difficulty of the next puzzle for a player with the stats st, who played the last puzzle at d
+//
+// the player moves a band down after leaving every puzzle unsolved, with an accuracy under 90%, with more than a hint
+// per puzzle or at half again the expected pace. A band up needs every puzzle solved with 97% accuracy, no hints and
+// under 3/4 of the expected pace. Otherwise the player stays at d.
+func Recommend(d rate.Difficulty, st Stats) rate.Difficulty {
+	switch {
+	case st.Puzzles == 0:
+		return d
+	case st.Solved == 0 || st.Accuracy < 0.9 || st.Hints > st.Puzzles || st.Pace > 1.5:
+		return max(d-1, rate.Easy)
+	case st.Solved == st.Puzzles && st.Accuracy >= 0.97 && st.Hints == 0 && st.Pace < 0.75:
+		return min(d+1, rate.Diabolical)
+	}
+	return d
+}
+
+// Recommend for the stats of the session and the difficulty of the puzzle being played
+//
+// the puzzle being played counts as unsolved until it's solved, so the recommendation is for after the puzzle.
+func (s *Session) Recommend() rate.Difficulty { return Recommend(s.level, s.Stats()) }
@@ -0,0 +1,363 @@
+// Play sessions on a puzzle, with hints, undo and a timestamped log of the moves
+package play
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/rate"
+	"github.com/phaul/sudoku/solve"
+)
+
+var (
+	ErrNoMoves    = errors.New("no moves to undo")                             // undo at the start of a session
+	ErrSolved     = errors.New("puzzle is already solved")                     // asking for a hint on a solved board
+	ErrNoBackdoor = errors.New("no single placement leads to a singles solve") // asking for a big hint without a backdoor
+	ErrBookmark   = errors.New("no such bookmark")                             // restoring a bookmark never saved
+)
+
+// kind of a move in a play session
+type MoveKind int
+
+const (
+	MovePlace    MoveKind = iota // a value placed
+	MoveErase                    // a value erased
+	MoveToggle                   // a candidate toggled
+	MoveUndo                     // the last move taken back
+	MoveHint                     // a hint requested
+	MoveBookmark                 // the position saved under a name
+	MoveRestore                  // a saved position restored
+	MoveClear                    // the candidates of a cell cleared
+	MoveAutoFill                 // the candidates of every cell filled in from the values
+)
+
+func (k MoveKind) String() string {
+	switch k {
+	case MovePlace:
+		return "place"
+	case MoveErase:
+		return "erase"
+	case MoveToggle:
+		return "toggle"
+	case MoveUndo:
+		return "undo"
+	case MoveHint:
+		return "hint"
+	case MoveBookmark:
+		return "bookmark"
+	case MoveRestore:
+		return "restore"
+	case MoveClear:
+		return "clear"
+	case MoveAutoFill:
+		return "autofill"
+	}
+	return fmt.Sprintf("MoveKind(%d)", int(k))
+}
+
+func (k MoveKind) MarshalText() ([]byte, error) { return []byte(k.String()), nil }
+
+// a user move in a play session
+type Move struct {
+	Kind    MoveKind  `json:"kind"`
+	At      time.Time `json:"at"`
+	Row     int       `json:"row,omitempty"`    // 1-9, 0 for moves without a cell
+	Column  int       `json:"column,omitempty"` // 1-9, 0 for moves without a cell
+	Value   cell.ValT `json:"value,omitempty"`
+	Level   HintLevel `json:"level,omitempty"`   // the most revealing rung of a hint ladder given
+	Name    string    `json:"name,omitempty"`    // name of the bookmark saved or restored
+	Mistake bool      `json:"mistake,omitempty"` // the placed value is not in the solution
+}
+
+// a puzzle being played, with the log of moves, and the summaries of the puzzles played before it
+type Session struct {
+	board    board.Board
+	solution board.Board
+	level    rate.Difficulty // rated difficulty of the puzzle
+	played   []Summary       // the puzzles finished or left with Next
+	moves    []Move
+	history  []board.Board          // boards before each undoable move
+	marks    map[string]board.Board // saved positions by bookmark name
+	watch    func(Move)             // called with every move as it's made, nil for none
+	now      func() time.Time
+	start    time.Time
+}
+
+// starts a session on puzzle b, which has to have a unique solution
+func New(ctx context.Context, b board.Board) (*Session, error) {
+	s := Session{now: time.Now}
+	if err := s.begin(ctx, b); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// moves the session on to puzzle b, which has to have a unique solution, keeping the summary of the puzzle played so
+// far for Stats
+//
+// the moves, undo history and bookmarks start over. The session stays on its puzzle if b can't be played.
+func (s *Session) Next(ctx context.Context, b board.Board) error {
+	sum := s.Summary()
+	if err := s.begin(ctx, b); err != nil {
+		return err
+	}
+	s.played = append(s.played, sum)
+	return nil
+}
+
+// starts playing puzzle b from scratch
+func (s *Session) begin(ctx context.Context, b board.Board) error {
+	r, err := solve.Auto(solve.Need{Count: true}).Solve(ctx, &b)
+	if err != nil {
+		return err
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	rt, err := rate.Rate(ctx, &b)
+	if err != nil {
+		return err
+	}
+
+	s.board, s.solution, s.level = b, r.Solution, rt.Difficulty
+	s.moves, s.history, s.marks = nil, nil, nil
+	s.start = s.now()
+	return nil
+}
+
+// the board as played so far
+func (s *Session) Board() board.Board { return s.board }
+
+// calls f with every move made from now on, after the board is changed, replacing an earlier f; nil stops the calls
+//
+// this is how a UI follows the session without polling the board
+func (s *Session) Watch(f func(Move)) { s.watch = f }
+
+// logs a move, remembering the board before it for undo
+func (s *Session) record(m Move, undoable bool, before board.Board) {
+	m.At = s.now()
+	s.moves = append(s.moves, m)
+	if undoable {
+		s.history = append(s.history, before)
+	}
+	if s.watch != nil {
+		s.watch(m)
+	}
+}
+
+// a move of kind at cell c with value v
+func moveAt(kind MoveKind, c coord.Coord, v cell.ValT) Move {
+	return Move{Kind: kind, Row: int(c.Y) + 1, Column: int(c.X) + 1, Value: v}
+}
+
+// places v at c
+func (s *Session) Place(c coord.Coord, v cell.ValT) error {
+	before := s.board
+	if err := s.board.Place(c, v, false); err != nil {
+		return err
+	}
+
+	m := moveAt(MovePlace, c, v)
+	m.Mistake = s.solution.At(c).Value != v
+	s.record(m, true, before)
+	return nil
+}
+
+// erases the value at c
+func (s *Session) Erase(c coord.Coord) error {
+	before := s.board
+	if err := s.board.Erase(c, false); err != nil {
+		return err
+	}
+
+	s.record(moveAt(MoveErase, c, 0), true, before)
+	return nil
+}
+
+// toggles candidate v at the empty cell c
+func (s *Session) Toggle(c coord.Coord, v cell.ValT) error {
+	before := s.board
+	if err := s.board.ToggleCandidate(c, v); err != nil {
+		return err
+	}
+
+	s.record(moveAt(MoveToggle, c, v), true, before)
+	return nil
+}
+
+// clears the candidates of the empty cell c
+func (s *Session) ClearCandidates(c coord.Coord) error {
+	before := s.board
+	if err := s.board.ClearCandidates(c); err != nil {
+		return err
+	}
+
+	s.record(moveAt(MoveClear, c, 0), true, before)
+	return nil
+}
+
+// fills in the candidates of every empty cell from the values placed so far, replacing the pencil marks
+func (s *Session) AutoFill() {
+	before := s.board
+	s.board.AutoFillCandidates()
+	s.record(Move{Kind: MoveAutoFill}, true, before)
+}
+
+// takes back the last move that changed the board
+func (s *Session) Undo() error {
+	if len(s.history) == 0 {
+		return ErrNoMoves
+	}
+
+	s.board = s.history[len(s.history)-1]
+	s.history = s.history[:len(s.history)-1]
+	s.record(Move{Kind: MoveUndo}, false, board.Board{})
+	return nil
+}
+
+// the next step the logic solver would take from the solution values placed so far
+func (s *Session) Hint(ctx context.Context) (solve.Step, error) {
+	_, st, err := s.next(ctx)
+	if err != nil {
+		return solve.Step{}, err
+	}
+
+	s.record(moveAt(MoveHint, st.Coord, st.Value), false, board.Board{})
+	return st, nil
+}
+
+// a placement after which the puzzle falls to singles alone, the way out for a stuck player
+//
+// out of the backdoor cells, as in solve.Backdoors, the one with the fewest candidates is given. If singles already
+// solve the board played so far, the big hint is the next single.
+func (s *Session) BigHint(ctx context.Context) (solve.Step, error) {
+	b, st, err := s.next(ctx)
+	if err != nil {
+		return solve.Step{}, err
+	}
+
+	if st.Technique == solve.Guess {
+		ds := solve.Backdoors(&b, &s.solution)
+		if len(ds) == 0 {
+			return solve.Step{}, ErrNoBackdoor
+		}
+		c := ds[0]
+		for _, d := range ds {
+			if b.At(d).PossibilityCount() < b.At(c).PossibilityCount() {
+				c = d
+			}
+		}
+		bb := b
+		bb.Fill(c, s.solution.At(c).Value)
+		st = solve.Step{Technique: solve.Guess, Coord: c, Value: s.solution.At(c).Value, Hash: bb.Hash()}
+	}
+
+	s.record(moveAt(MoveHint, st.Coord, st.Value), false, board.Board{})
+	return st, nil
+}
+
+// the next step of the logic solver, and the board it's taken on: the board played so far without the mistakes
+func (s *Session) next(ctx context.Context) (board.Board, solve.Step, error) {
+	b := s.board
+	sv := s.solution.Values()
+	for ix, v := range b.Values() {
+		if v != 0 && v != sv[ix] {
+			// don't build on mistakes
+			b.Erase(coord.Itoc(ix), true)
+		}
+	}
+	if err := b.Warm(board.RecomputeMarks); err != nil {
+		return b, solve.Step{}, err
+	}
+
+	bb := b
+	r, err := solve.Logic{}.Solve(ctx, &bb)
+	if err != nil {
+		return b, solve.Step{}, err
+	}
+	if len(r.Trace) == 0 {
+		return b, solve.Step{}, ErrSolved
+	}
+	return b, r.Trace[0], nil
+}
+
+// saves the position played so far under name, replacing an earlier bookmark of the same name
+//
+// bookmarks are for trying out a candidate by hand: save, play on, and restore if it leads to a contradiction
+func (s *Session) Bookmark(name string) {
+	if s.marks == nil {
+		s.marks = map[string]board.Board{}
+	}
+	s.marks[name] = s.board
+	s.record(Move{Kind: MoveBookmark, Name: name}, false, board.Board{})
+}
+
+// goes back to the position saved under name, the restore itself can be undone
+func (s *Session) Restore(name string) error {
+	b, ok := s.marks[name]
+	if !ok {
+		return fmt.Errorf("restoring %q: %w", name, ErrBookmark)
+	}
+
+	before := s.board
+	s.board = b
+	s.record(Move{Kind: MoveRestore, Name: name}, true, before)
+	return nil
+}
+
+// names of the bookmarks, sorted
+func (s *Session) Bookmarks() []string { return slices.Sorted(maps.Keys(s.marks)) }
+
+// summary statistics of the puzzle being played
+type Summary struct {
+	Solved     bool            `json:"solved"`
+	Difficulty rate.Difficulty `json:"difficulty"` // as rated by rate.Rate
+	Duration   time.Duration   `json:"duration"`   // time to solve, or time spent so far
+	Moves      int             `json:"moves"`
+	Placements int             `json:"placements"` // place moves, the mistakes included
+	Mistakes   int             `json:"mistakes"`
+	Hints      int             `json:"hints"`
+	Undos      int             `json:"undos"`
+}
+
+func (s *Session) Summary() Summary {
+	r := Summary{Solved: s.board.Values() == s.solution.Values(), Difficulty: s.level, Moves: len(s.moves)}
+	end := s.now()
+
+	for _, m := range s.moves {
+		if m.Kind == MovePlace {
+			r.Placements++
+		}
+		switch {
+		case m.Mistake:
+			r.Mistakes++
+		case m.Kind == MoveHint:
+			r.Hints++
+		case m.Kind == MoveUndo:
+			r.Undos++
+		}
+	}
+	if r.Solved && len(s.moves) > 0 {
+		end = s.moves[len(s.moves)-1].At
+	}
+	r.Duration = end.Sub(s.start)
+	return r
+}
+
+// the session as JSON: start time, moves and summary of the puzzle being played, and the stats of the session
+func (s *Session) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Start   time.Time `json:"start"`
+		Moves   []Move    `json:"moves"`
+		Summary Summary   `json:"summary"`
+		Stats   Stats     `json:"stats"`
+	}{s.start, s.moves, s.Summary(), s.Stats()})
+}
@@ -0,0 +1,50 @@
+package play
+
+import "time"
+
+// statistics of the puzzles of a session, the one being played included
+type Stats struct {
+	Puzzles    int           `json:"puzzles"`
+	Solved     int           `json:"solved"`
+	Duration   time.Duration `json:"duration"` // time spent on all the puzzles
+	Fastest    time.Duration `json:"fastest"`  // quickest solve, 0 without one
+	Average    time.Duration `json:"average"`  // mean time of the solves, 0 without one
+	Pace       float64       `json:"pace"`     // mean solve time over the ExpectedTime of the difficulty, 0 without one
+	Moves      int           `json:"moves"`
+	Placements int           `json:"placements"`
+	Mistakes   int           `json:"mistakes"`
+	Hints      int           `json:"hints"`
+	Undos      int           `json:"undos"`
+	Accuracy   float64       `json:"accuracy"` // share of the placements that weren't mistakes, 1 without placements
+}
+
+// aggregates the summaries of the puzzles played in the session with Next and the one being played
+func (s *Session) Stats() Stats {
+	r := Stats{Accuracy: 1}
+	solves := time.Duration(0)
+	for _, sum := range append(s.played, s.Summary()) {
+		r.Puzzles++
+		r.Duration += sum.Duration
+		r.Moves += sum.Moves
+		r.Placements += sum.Placements
+		r.Mistakes += sum.Mistakes
+		r.Hints += sum.Hints
+		r.Undos += sum.Undos
+		if sum.Solved {
+			r.Solved++
+			solves += sum.Duration
+			r.Pace += float64(sum.Duration) / float64(ExpectedTime[sum.Difficulty])
+			if r.Fastest == 0 || sum.Duration < r.Fastest {
+				r.Fastest = sum.Duration
+			}
+		}
+	}
+	if r.Solved > 0 {
+		r.Average = solves / time.Duration(r.Solved)
+		r.Pace /= float64(r.Solved)
+	}
+	if r.Placements > 0 {
+		r.Accuracy = float64(r.Placements-r.Mistakes) / float64(r.Placements)
+	}
+	return r
+}
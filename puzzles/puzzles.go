@@ -0,0 +1,132 @@
+// A curated library of sample puzzles in the 81 character line format, embedded in the binary
+//
+// Standard puzzles are grouped by difficulty band, variant puzzles by variant. Packs loaded at run time join the
+// embedded standard puzzles of their band for Pick.
+package puzzles
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+//go:embed samples
+var samples embed.FS
+
+// difficulty band of a sample, named after the hardest technique the logic solver needs
+type Difficulty int
+
+const (
+	Easy   Difficulty = iota // naked singles only
+	Medium                   // needs hidden singles
+	Hard                     // needs guessing
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Hard:
+		return "hard"
+	}
+	return fmt.Sprintf("Difficulty(%d)", int(d))
+}
+
+// standard sudoku samples of difficulty d
+func Samples(d Difficulty) []string {
+	return lines("samples/standard/" + d.String() + ".sdm")
+}
+
+// standard puzzles loaded from packs, per difficulty band
+var (
+	mu     sync.RWMutex
+	loaded [Hard + 1][]string
+)
+
+// adds the puzzles of the sdm pack r to difficulty band d, for Pick to choose from
+//
+// nothing is added if any of the non-empty lines isn't an 81 character puzzle of digits, '0' or '.' for empty cells
+func Load(r io.Reader, d Difficulty) error {
+	if d < Easy || d > Hard {
+		return fmt.Errorf("loading pack: unknown difficulty %v", d)
+	}
+
+	ps := []string{}
+	s := bufio.NewScanner(r)
+	n := 0
+	for s.Scan() {
+		n++
+		l := strings.TrimSpace(s.Text())
+		switch {
+		case l == "":
+			continue
+		case len(l) != 9*9:
+			return fmt.Errorf("loading pack: line %d has %d characters instead of 81", n, len(l))
+		}
+		if p := strings.IndexFunc(l, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); p >= 0 {
+			return fmt.Errorf("loading pack: invalid character %q at line %d, column %d", l[p], n, p+1)
+		}
+		ps = append(ps, l)
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("loading pack: line %d: %w", n+1, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	loaded[d] = append(loaded[d], ps...)
+	return nil
+}
+
+// a puzzle of difficulty d picked uniformly at random out of the samples and the loaded packs of the band
+//
+// returns false if the band has no puzzles. safe for concurrent use as long as rng isn't shared.
+func Pick(rng *rand.Rand, d Difficulty) (string, bool) {
+	if d < Easy || d > Hard {
+		return "", false
+	}
+	ss := Samples(d)
+
+	mu.RLock()
+	defer mu.RUnlock()
+	n := len(ss) + len(loaded[d])
+	if n == 0 {
+		return "", false
+	}
+	i := rng.Intn(n)
+	if i < len(ss) {
+		return ss[i], true
+	}
+	return loaded[d][i-len(ss)], true
+}
+
+// names of the variants with samples
+func Variants() []string {
+	es, _ := samples.ReadDir("samples/variants")
+	vs := []string{}
+
+	for _, e := range es {
+		vs = append(vs, strings.TrimSuffix(e.Name(), ".sdm"))
+	}
+	return vs
+}
+
+// samples of variant v, nil for unknown variants
+func VariantSamples(v string) []string {
+	return lines("samples/variants/" + v + ".sdm")
+}
+
+// non-empty lines of the embedded file fn
+func lines(fn string) []string {
+	b, err := samples.ReadFile(fn)
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(b))
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/rate"
+	"github.com/phaul/sudoku/solve"
+)
+
+// a rated puzzle of a batch
+type rated struct {
+	formats.Entry
+	rating rate.Rating
+}
+
+// rates every puzzle of the sdm or puzzle bank files without the disabled techniques, printing a summary to w and
+// writing a per puzzle report to report if not empty. The report format is picked by its extension, .csv or .json.
+// Unless out is nil, it gets every rating as soon as it's taken.
+func rateBatch(
+	ctx context.Context, w io.Writer, out *jsonLines, files []string, report string, disabled solve.TechniqueSet,
+) error {
+	rs := []rated{}
+	add := func(r rated) {
+		rs = append(rs, r)
+		if out != nil {
+			jr := jsonResultOf(r.Entry)
+			jr.Rating = &r.rating
+			out.write(jr)
+		}
+	}
+
+	for _, fn := range files {
+		err := readPuzzles(fn, func(e formats.Entry, b board.Board, err error) error {
+			var invalid *board.InvalidPuzzleError
+			if errors.As(err, &invalid) {
+				// well formed, but unsolvable
+				add(rated{Entry: e, rating: rate.Rating{Status: solve.Unsolvable}})
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", e.File, e.Line, err)
+			}
+			rt, err := rate.RateWithout(ctx, &b, disabled)
+			if err != nil {
+				return err
+			}
+			add(rated{Entry: e, rating: rt})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if out != nil {
+		if err := out.error(); err != nil {
+			return err
+		}
+	}
+	summary(w, rs)
+
+	switch filepath.Ext(report) {
+	case "":
+		return nil
+	case ".csv":
+		return writeReport(report, rs, reportCSV)
+	case ".json":
+		return writeReport(report, rs, reportJSON)
+	default:
+		return fmt.Errorf("unknown report format %q", report)
+	}
+}
+
+// prints the difficulty histogram, the technique frequencies and the puzzles without a unique solution
+func summary(w io.Writer, rs []rated) {
+	bands := map[rate.Difficulty]int{}
+	steps := map[solve.Technique]int{}
+	puzzles := map[solve.Technique]int{}
+	outliers := []rated{}
+
+	for _, r := range rs {
+		if r.rating.Status != solve.Solved {
+			outliers = append(outliers, r)
+			continue
+		}
+		bands[r.rating.Difficulty]++
+		for t, n := range r.rating.Techniques {
+			steps[t] += n
+			puzzles[t]++
+		}
+	}
+
+	fmt.Fprintf(w, "%d puzzles\n\ndifficulty\n", len(rs))
+	for d := rate.Easy; d <= rate.Diabolical; d++ {
+		fmt.Fprintf(w, "%-10s %6d %s\n", d, bands[d], strings.Repeat("#", bands[d]*50/max(len(rs), 1)))
+	}
+
+	fmt.Fprintf(w, "\ntechnique          steps puzzles\n")
+	for t := solve.NakedSingle; t <= solve.Guess; t++ {
+		fmt.Fprintf(w, "%-15s %8d %7d\n", t, steps[t], puzzles[t])
+	}
+
+	if len(outliers) > 0 {
+		fmt.Fprintf(w, "\noutliers\n")
+		sort.SliceStable(outliers, func(i, j int) bool { return outliers[i].rating.Status < outliers[j].rating.Status })
+		for _, r := range outliers {
+			fmt.Fprintf(w, "%s:%d %s\n", r.File, r.Line, r.rating.Status)
+		}
+	}
+}
+
+func writeReport(fn string, rs []rated, f func(io.Writer, []rated) error) error {
+	return writeFile(fn, func(w io.Writer) error { return f(w, rs) })
+}
+
+func reportCSV(w io.Writer, rs []rated) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"file", "line", "id", "puzzle", "bank rating", "status", "difficulty", "score", "naked singles",
+		"hidden singles", "guesses", "source", "author", "license", "date", "source rating"})
+
+	for _, r := range rs {
+		d, score := "", ""
+		if r.rating.Status == solve.Solved {
+			d, score = r.rating.Difficulty.String(), strconv.FormatFloat(r.rating.Score, 'f', 3, 64)
+		}
+		c.Write([]string{
+			r.File, strconv.Itoa(r.Line), r.ID, r.Puzzle, bankRating(r.Entry), r.rating.Status.String(), d, score,
+			strconv.Itoa(r.rating.Techniques[solve.NakedSingle]),
+			strconv.Itoa(r.rating.Techniques[solve.HiddenSingle]),
+			strconv.Itoa(r.rating.Techniques[solve.Guess]),
+			r.Meta.Source, r.Meta.Author, r.Meta.License, r.Meta.Date, r.Meta.Rating,
+		})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// the puzzle bank rating of e, empty if e is not from a puzzle bank
+func bankRating(e formats.Entry) string {
+	if e.ID == "" {
+		return ""
+	}
+	return strconv.FormatFloat(e.Rating, 'f', -1, 64)
+}
+
+func reportJSON(w io.Writer, rs []rated) error {
+	type entry struct {
+		File       string         `json:"file"`
+		Line       int            `json:"line"`
+		ID         string         `json:"id,omitempty"`
+		Puzzle     string         `json:"puzzle"`
+		BankRating float64        `json:"bank_rating,omitempty"`
+		Status     string         `json:"status"`
+		Difficulty string         `json:"difficulty,omitempty"`
+		Score      float64        `json:"score,omitempty"`
+		Limit      string         `json:"limit,omitempty"`
+		Techniques map[string]int `json:"techniques,omitempty"`
+		Source     string         `json:"source,omitempty"`
+		Author     string         `json:"author,omitempty"`
+		License    string         `json:"license,omitempty"`
+		Date       string         `json:"date,omitempty"`
+		Rating     string         `json:"source_rating,omitempty"`
+	}
+
+	es := []entry{}
+	for _, r := range rs {
+		e := entry{File: r.File, Line: r.Line, ID: r.ID, Puzzle: r.Puzzle, BankRating: r.Entry.Rating,
+			Status: r.rating.Status.String(), Source: r.Meta.Source, Author: r.Meta.Author, License: r.Meta.License,
+			Date: r.Meta.Date, Rating: r.Meta.Rating}
+		if r.rating.Status == solve.Solved {
+			e.Difficulty = r.rating.Difficulty.String()
+			e.Score, e.Limit = r.rating.Score, r.rating.Limit.String()
+			e.Techniques = map[string]int{}
+			for t, n := range r.rating.Techniques {
+				e.Techniques[t.String()] = n
+			}
+		}
+		es = append(es, e)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(es)
+}
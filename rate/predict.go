@@ -0,0 +1,47 @@
+package rate
+
+import (
+	"context"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/solve"
+)
+
+// cheap indicators of the difficulty of a puzzle, taken without searching
+type Prediction struct {
+	Clues      int
+	Tightness  float64    // of the candidates of the puzzle, as in board.Histogram
+	Cascade    int        // empty cells the singles fill before they run out
+	Difficulty Difficulty // the band the singles cascade points to
+}
+
+// predicts the difficulty of b from its clues, candidates and singles cascade
+//
+// the cascade is the start of every logic solve, naked singles first, then hidden singles. If naked singles alone solve
+// b it's Easy, if hidden singles are needed too it's Medium, otherwise Hard. For a puzzle with a unique solution this
+// is the band Rate gives, except that Hard stands for Diabolical too, telling them apart takes the search. The
+// solutions are not counted, so Rate still has to confirm a puzzle of unknown uniqueness. Boards whose candidates can't
+// be derived from their values predict Hard.
+func Predict(b *board.Board) Prediction {
+	p := Prediction{Clues: b.Clues(), Difficulty: Hard}
+
+	bb := *b
+	if bb.Warm(board.RecomputeMarks) != nil {
+		return p
+	}
+	p.Tightness = bb.Histogram().Tightness()
+
+	// without guessing the logic solver doesn't search, ctx can't expire
+	ctx := context.Background()
+	naked := solve.Logic{Disabled: solve.TechniquesOf(solve.HiddenSingle, solve.Guess)}
+	if r, _ := naked.Solve(ctx, &bb); r.Status == solve.Solved {
+		p.Cascade, p.Difficulty = len(r.Trace), Easy
+		return p
+	}
+	r, _ := solve.Logic{Disabled: solve.TechniquesOf(solve.Guess)}.Solve(ctx, &bb)
+	p.Cascade = len(r.Trace)
+	if r.Status == solve.Solved {
+		p.Difficulty = Medium
+	}
+	return p
+}
@@ -0,0 +1,137 @@
+// Difficulty rating of puzzles, by the techniques the logic solver needs for them
+package rate
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/solve"
+)
+
+// difficulty band of a puzzle, based on the hardest technique needed
+type Difficulty int
+
+const (
+	Easy       Difficulty = iota // naked singles only
+	Medium                       // needs hidden singles
+	Hard                         // needs guessing, without taking a guess back
+	Diabolical                   // needs guesses that are taken back
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Hard:
+		return "hard"
+	case Diabolical:
+		return "diabolical"
+	}
+	return fmt.Sprintf("difficulty(%d)", int(d))
+}
+
+func (d Difficulty) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+func (d *Difficulty) UnmarshalText(text []byte) error {
+	v, err := ParseDifficulty(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// the difficulty named s, as in Difficulty.String
+func ParseDifficulty(s string) (Difficulty, error) {
+	for d := Easy; d <= Diabolical; d++ {
+		if d.String() == s {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown difficulty %q", s)
+}
+
+// version of the rating logic, bumped when a change to Rate changes the ratings it gives
+const version = 2
+
+// identifies the rater: the rating logic and the search parameters of the logic solver, solve.Profile
+//
+// Rate gives the same rating for the same puzzle as long as the fingerprint is the same, so stored ratings can be reused
+func Fingerprint() string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d %+v", version, solve.Profile)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// rating of a puzzle, the fields other than Status are only set for puzzles with a unique solution
+type Rating struct {
+	Status     solve.Status            `json:"status"`
+	Difficulty Difficulty              `json:"difficulty"`
+	Score      float64                 `json:"score"`                // the band, plus a fraction growing with Work
+	Limit      solve.Technique         `json:"limit"`                // hardest technique needed
+	Work       int                     `json:"work"`                 // steps of Limit, or guesses taken back for Diabolical
+	Techniques map[solve.Technique]int `json:"techniques,omitempty"` // number of steps taken by technique
+}
+
+// orders ratings from the least to the most demanding: by Score, then by the number of hidden singles
+//
+// the result is negative, zero or positive as r is less, as demanding or more demanding than o
+func (r Rating) Compare(o Rating) int {
+	if c := cmp.Compare(r.Score, o.Score); c != 0 {
+		return c
+	}
+	return cmp.Compare(r.Techniques[solve.HiddenSingle], o.Techniques[solve.HiddenSingle])
+}
+
+// rates b by solving it with the logic solver, puzzles without a unique solution are not rated further
+//
+// the candidates of b are recomputed from its values, so boards with user placements and pencil marks can be rated
+func Rate(ctx context.Context, b *board.Board) (Rating, error) {
+	return RateWithout(ctx, b, 0)
+}
+
+// Rate with the logic solver not using the disabled techniques, puzzles it gets stuck on are rated solve.Stuck
+func RateWithout(ctx context.Context, b *board.Board, disabled solve.TechniqueSet) (Rating, error) {
+	bb := *b
+	if bb.Warm(board.RecomputeMarks) != nil {
+		return Rating{Status: solve.Unsolvable}, nil
+	}
+	b = &bb
+
+	r, err := solve.Auto(solve.Need{Count: true}).Solve(ctx, b)
+	if err != nil || r.Status != solve.Solved {
+		return Rating{Status: r.Status}, err
+	}
+
+	if r, err = (solve.Logic{Disabled: disabled}).Solve(ctx, b); err != nil || r.Status != solve.Solved {
+		return Rating{Status: r.Status}, err
+	}
+
+	rt := Rating{Status: r.Status, Techniques: map[solve.Technique]int{}}
+	for _, st := range r.Trace {
+		rt.Techniques[st.Technique]++
+		rt.Limit = max(rt.Limit, st.Technique)
+	}
+	rt.Work = rt.Techniques[rt.Limit]
+	switch rt.Limit {
+	case solve.HiddenSingle:
+		rt.Difficulty = Medium
+	case solve.Guess:
+		rt.Difficulty = Hard
+		backtracks := 0
+		for _, n := range r.Stats.Backtracks {
+			backtracks += n
+		}
+		if backtracks > 0 {
+			rt.Difficulty, rt.Work = Diabolical, backtracks
+		}
+	}
+	// about a tenth of the band at 1 step, half of it at 10 steps
+	rt.Score = float64(rt.Difficulty) + float64(rt.Work)/float64(rt.Work+10)
+	return rt, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/phaul/sudoku/rate"
+)
+
+// ratings of the puzzles of a pack, written next to it so later builds only rate the puzzles they haven't seen
+type ratingCache struct {
+	Ratings map[string]cachedRating `json:"ratings"` // by puzzle in the 81 character line format
+}
+
+// a rating in a rating cache
+type cachedRating struct {
+	Fingerprint string `json:"fingerprint"` // of the rater that gave the rating, as in rate.Fingerprint
+	rate.Rating
+}
+
+// reads the rating cache fn, a missing file is an empty cache
+func loadRatings(fn string) (ratingCache, error) {
+	c := ratingCache{}
+	f, err := os.Open(fn)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		c.Ratings = map[string]cachedRating{}
+		return c, nil
+	case err != nil:
+		return c, err
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&c); err != nil {
+		return c, fmt.Errorf("%s: %w", fn, err)
+	}
+	if c.Ratings == nil {
+		c.Ratings = map[string]cachedRating{}
+	}
+	return c, nil
+}
+
+// the rating of puzzle, if it's in the cache and was given by a rater with fingerprint fp
+func (c ratingCache) get(puzzle, fp string) (rate.Rating, bool) {
+	cr, ok := c.Ratings[puzzle]
+	if !ok || cr.Fingerprint != fp {
+		return rate.Rating{}, false
+	}
+	return cr.Rating, true
+}
+
+// stores the rating r of puzzle, given by a rater with fingerprint fp
+func (c ratingCache) put(puzzle, fp string, r rate.Rating) {
+	c.Ratings[puzzle] = cachedRating{Fingerprint: fp, Rating: r}
+}
+
+func (c ratingCache) write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
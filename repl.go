@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/play"
+	"github.com/phaul/sudoku/solve"
+)
+
+// commands of the -repl, with their arguments and what they do
+var replCommands = [][3]string{
+	{"load", "puzzle", "start playing the puzzle, in the line or hodoku library format"},
+	{"print", "[candidates]", "print the board, with the candidates of the empty cells if asked"},
+	{"set", "rXcY digit", "place the digit in the cell"},
+	{"erase", "rXcY", "erase the value of the cell"},
+	{"toggle", "rXcY digit", "toggle the candidate of the empty cell"},
+	{"clear", "rXcY", "clear the candidates of the empty cell"},
+	{"autofill", "", "fill in the candidates of every empty cell from the values"},
+	{"candidates", "rXcY", "list the candidates of the cell and why the others are out"},
+	{"hint", "", "the next step of the logic solver"},
+	{"solve", "", "print the solution of the board played so far"},
+	{"undo", "", "take back the last move"},
+	{"stats", "", "the statistics of the puzzles loaded so far, as json"},
+	{"recommend", "", "the difficulty to play next, by the statistics"},
+	{"help", "", "list the commands"},
+	{"quit", "", "leave the repl"},
+}
+
+// a play session driven by the commands of a -repl
+type replState struct {
+	ctx     context.Context
+	w       io.Writer
+	layout  coord.Layout
+	theme   *board.Theme
+	session *play.Session
+}
+
+// reads commands from r until it's exhausted or a quit, writing their output to w
+//
+// puzzle, when not empty, is loaded first. prompt is written before every command, so that it can be left empty for
+// scripts. Failing commands print their error and the repl goes on.
+func repl(ctx context.Context, r io.Reader, w io.Writer, l coord.Layout, th *board.Theme, puzzle, prompt string) error {
+	st := replState{ctx: ctx, w: w, layout: l, theme: th}
+	if puzzle != "" {
+		if err := st.load(puzzle); err != nil {
+			return err
+		}
+	}
+
+	s := bufio.NewScanner(r)
+	for fmt.Fprint(w, prompt); s.Scan(); fmt.Fprint(w, prompt) {
+		fs := strings.Fields(s.Text())
+		if len(fs) == 0 {
+			continue
+		}
+		if fs[0] == "quit" || fs[0] == "exit" {
+			return nil
+		}
+		if err := st.run(fs[0], fs[1:]); err != nil {
+			fmt.Fprintln(w, "error:", err)
+		}
+	}
+	return s.Err()
+}
+
+// runs the command cmd with the arguments args
+func (st *replState) run(cmd string, args []string) error {
+	switch cmd {
+	case "help":
+		for _, c := range replCommands {
+			fmt.Fprintf(st.w, "  %-24s %s\n", strings.TrimSpace(c[0]+" "+c[1]), c[2])
+		}
+		return nil
+	case "load":
+		if len(args) == 0 {
+			return errors.New("usage: load puzzle")
+		}
+		return st.load(strings.Join(args, " "))
+	}
+
+	if st.session == nil {
+		return fmt.Errorf("no puzzle loaded, load one first")
+	}
+	var (
+		c   coord.Coord
+		v   cell.ValT
+		err error
+	)
+	switch cmd {
+	case "set", "toggle":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: %s rXcY digit", cmd)
+		}
+		if c, err = parseCell(args[0]); err != nil {
+			return err
+		}
+		if v, err = parseDigit(args[1]); err != nil {
+			return err
+		}
+	case "erase", "clear", "candidates":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: %s rXcY", cmd)
+		}
+		if c, err = parseCell(args[0]); err != nil {
+			return err
+		}
+	}
+
+	switch cmd {
+	case "print":
+		if len(args) > 1 || len(args) == 1 && args[0] != "candidates" {
+			return errors.New("usage: print [candidates]")
+		}
+		b := st.session.Board()
+		return b.Render(st.w, board.Style{Theme: st.theme, Candidates: len(args) == 1})
+	case "set":
+		err = st.session.Place(c, v)
+	case "erase":
+		err = st.session.Erase(c)
+	case "toggle":
+		err = st.session.Toggle(c, v)
+	case "clear":
+		err = st.session.ClearCandidates(c)
+	case "autofill":
+		st.session.AutoFill()
+	case "undo":
+		err = st.session.Undo()
+	case "recommend":
+		fmt.Fprintln(st.w, st.session.Recommend())
+		return nil
+	case "stats":
+		e := json.NewEncoder(st.w)
+		e.SetIndent("", "  ")
+		return e.Encode(st.session.Stats())
+	case "candidates":
+		b := st.session.Board()
+		e, err := b.Explain(c)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(st.w, e)
+		return nil
+	case "hint":
+		h, err := st.session.Hint(st.ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(st.w, h)
+		return nil
+	case "solve":
+		b := st.session.Board()
+		r, err := solve.Auto(solve.Need{Count: true}).Solve(st.ctx, &b)
+		if err == nil {
+			err = r.Err()
+		}
+		if err != nil {
+			return err
+		}
+		return r.Solution.Render(st.w, board.Style{Theme: st.theme})
+	default:
+		return fmt.Errorf("unknown command %q, try help", cmd)
+	}
+	if err != nil {
+		return err
+	}
+	s := board.Style{Theme: st.theme}
+	if cmd == "set" {
+		// the placement just made
+		s.Marks = []coord.Coord{c}
+	}
+	b := st.session.Board()
+	return b.Render(st.w, s)
+}
+
+// starts a session on the puzzle p
+func (st *replState) load(p string) error {
+	if formats.IsSukaku(p) {
+		// a session works out the candidates from the values, losing the clues of a sukaku
+		return errors.New("sukaku puzzles can't be played, only solved")
+	}
+	b, err := parsePuzzle(st.layout, p)
+	if err != nil {
+		return err
+	}
+	if st.session == nil {
+		st.session, err = play.New(st.ctx, b)
+	} else {
+		// the session goes on, keeping the statistics of the puzzles before
+		err = st.session.Next(st.ctx, b)
+	}
+	if err != nil {
+		return err
+	}
+	return b.Render(st.w, board.Style{Theme: st.theme})
+}
+
+// parses a cell in the rXcY notation
+func parseCell(s string) (coord.Coord, error) {
+	if len(s) != 4 || (s[0] != 'r' && s[0] != 'R') || (s[2] != 'c' && s[2] != 'C') ||
+		s[1] < '1' || s[1] > '9' || s[3] < '1' || s[3] > '9' {
+		return coord.Coord{}, fmt.Errorf("invalid cell %q, expected rXcY like r4c7", s)
+	}
+	return coord.Itoc(int(s[1]-'1')*9 + int(s[3]-'1')), nil
+}
+
+// parses a digit of 1 to 9
+func parseDigit(s string) (cell.ValT, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 9 {
+		return 0, fmt.Errorf("invalid digit %q", s)
+	}
+	return cell.ValT(n), nil
+}
@@ -0,0 +1,559 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cache"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/gen"
+	"github.com/phaul/sudoku/rate"
+	"github.com/phaul/sudoku/solve"
+)
+
+// largest request body -serve reads, a board in json is under 2K
+const maxRequest = 64 << 10
+
+// limit of a request when -timeout doesn't give one, a /hint of a hard puzzle runs the logic solver for long
+const requestTimeout = 30 * time.Second
+
+// time the requests in flight get to finish once the server is shut down
+const shutdownTimeout = 5 * time.Second
+
+// the endpoints of -serve with their methods
+var endpoints = [][3]string{
+	{"POST", "/solve", "the status and solution of the puzzle in the body"},
+	{"POST", "/rate", "the rating of the puzzle in the body"},
+	{"POST", "/hint", "a next step on the board in the body, one its pencil marks show if there is one"},
+	{"POST", "/generate", `a puzzle, of the difficulty of an optional {"difficulty": "hard", "seed": 1} body`},
+	{"GET", "/healthz", "ok while the server runs"},
+	{"GET", "/readyz", "ok while the server takes requests, unavailable once it's shutting down"},
+}
+
+// the query options of the endpoints
+var queryOptions = [][2]string{
+	{"solver", "backend of /solve: auto, logic, dlx or compact"},
+	{"enable", "techniques /solve, /rate and /hint use only, comma separated"},
+	{"disable", "techniques /solve, /rate and /hint don't use, on top of the ones -disable turns off"},
+	{"timeout", "limit of the request, at most the one of the server"},
+	{"trace", "true for the trace document of the solve in the answer of /solve"},
+	{"count", "false to let /solve skip telling unique puzzles from ones with multiple solutions"},
+}
+
+// the http server of -serve, boards are of layout and puzzles are generated as -generate would
+type server struct {
+	layout  coord.Layout
+	variant string
+	sizes   []int                        // weights of the cage sizes of a killer
+	bank    string                       // -bank file of the generated puzzles, empty for random grids
+	workers int                          // workers of a generate with a difficulty
+	timeout time.Duration                // limit of a request, requestTimeout if 0
+	disable solve.TechniqueSet           // techniques no request can use
+	answers *cache.Cache[answerKey, any] // answers of /solve and /rate, nil to solve every request
+	cert    string                       // -tls-cert file, plain http if empty
+	key     string                       // -tls-key file of cert
+	auth    authenticator                // access control of the POST endpoints, open to anyone if nil
+
+	draining atomic.Bool // the server is shutting down, /readyz fails
+}
+
+// the answer of /healthz and /readyz
+type statusResult struct {
+	Status string `json:"status"`
+}
+
+// what the answer of /solve or /rate depends on
+//
+// answers are keyed by the board itself rather than its canonical form: the solution of an equivalent puzzle is the
+// transformed solution, and the logic solver can take another path, with another rating, on it
+type answerKey struct {
+	path    string
+	options options // the timeout is zero, it doesn't change a finished answer
+	values  [9 * 9]cell.ValT
+	marks   [9 * 9]uint16
+	cages   string
+}
+
+// the solver options of a request
+type options struct {
+	backend string
+	disable solve.TechniqueSet
+	timeout time.Duration
+	trace   bool // the answer of /solve has the trace document
+	count   bool // /solve tells unique puzzles from ones with multiple solutions
+}
+
+// the answer of /solve, with the trace document of the solve if the request asked for it
+type solveResult struct {
+	jsonResult
+	Trace *formats.TraceDocument `json:"trace,omitempty"`
+}
+
+// the answer of /hint, the result of the solve with a step the user can take next
+//
+// a puzzle that needs a guess next is stuck, without a hint
+type hintResult struct {
+	jsonResult
+	Hint string             `json:"hint,omitempty"` // the step as -steps prints it
+	Step *formats.TraceStep `json:"step,omitempty"` // the step as in the trace document of -json
+}
+
+// the request of /generate
+type generateRequest struct {
+	Difficulty *rate.Difficulty `json:"difficulty"` // any difficulty if missing
+	Seed       *int64           `json:"seed"`       // random if missing
+}
+
+// serves the endpoints on addr until the server fails or ctx is done
+//
+// once ctx is done the server is shut down, the requests in flight get shutdownTimeout to finish before they are
+// cancelled, and it returns nil if they finished in time
+func serveMain(ctx context.Context, log io.Writer, addr string, sv *server) error {
+	base, abort := context.WithCancel(context.WithoutCancel(ctx))
+	defer abort()
+	s := http.Server{
+		Addr:              addr,
+		Handler:           sv.handler(),
+		BaseContext:       func(net.Listener) context.Context { return base },
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if (sv.cert == "") != (sv.key == "") {
+		return errors.New("-tls-cert and -tls-key go together")
+	}
+	scheme := "http"
+	if sv.cert != "" {
+		scheme = "https"
+	}
+	fmt.Fprintf(log, "serving on %s://%s\n", scheme, addr)
+	for _, e := range endpoints {
+		fmt.Fprintf(log, "  %-4s %-10s %s\n", e[0], e[1], e[2])
+	}
+	fmt.Fprintln(log, "query options:")
+	for _, o := range queryOptions {
+		fmt.Fprintf(log, "  %-15s %s\n", o[0], o[1])
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	shut := make(chan error, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		sv.draining.Store(true)
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err := s.Shutdown(sctx)
+		abort()
+		shut <- err
+	}()
+
+	var err error
+	if sv.cert != "" {
+		err = s.ListenAndServeTLS(sv.cert, sv.key)
+	} else {
+		err = s.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-shut
+}
+
+// the routes of the endpoints, the health endpoints are open even with an authenticator
+func (sv *server) handler() http.Handler {
+	m := http.NewServeMux()
+	m.HandleFunc("POST /solve", authenticated(sv.auth, sv.solve))
+	m.HandleFunc("POST /rate", authenticated(sv.auth, sv.rate))
+	m.HandleFunc("POST /hint", authenticated(sv.auth, sv.hint))
+	m.HandleFunc("POST /generate", authenticated(sv.auth, sv.generate))
+	m.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		reply(w, http.StatusOK, statusResult{Status: "ok"})
+	})
+	m.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if sv.draining.Load() {
+			reply(w, http.StatusServiceUnavailable, statusResult{Status: "shutting down"})
+			return
+		}
+		reply(w, http.StatusOK, statusResult{Status: "ok"})
+	})
+	return m
+}
+
+// the options of the request r from its query, the names of queryOptions
+//
+// a request can only narrow what the server allows: its timeout is capped by the one of the server, and the techniques
+// the server disables stay disabled
+func (sv *server) options(r *http.Request) (options, error) {
+	o := options{backend: "auto", disable: sv.disable, timeout: requestTimeout, count: true}
+	if sv.timeout > 0 {
+		o.timeout = sv.timeout
+	}
+
+	q := r.URL.Query()
+	if n := q.Get("solver"); n != "" {
+		if _, ok := solve.Solvers[n]; !ok && n != "auto" {
+			return o, fmt.Errorf("unknown solver %q", n)
+		}
+		o.backend = n
+	}
+	var enable, disable techniqueList
+	for _, t := range [...]struct {
+		l *techniqueList
+		n string
+	}{{&enable, "enable"}, {&disable, "disable"}} {
+		if v := q.Get(t.n); v != "" {
+			if err := t.l.Set(v); err != nil {
+				return o, err
+			}
+		}
+	}
+	o.disable |= disabledTechniques(enable, disable)
+	if v := q.Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return o, fmt.Errorf("timeout: %w", err)
+		}
+		if d > 0 {
+			o.timeout = min(o.timeout, d)
+		}
+	}
+	for _, f := range [...]struct {
+		b *bool
+		n string
+	}{{&o.trace, "trace"}, {&o.count, "count"}} {
+		if v := q.Get(f.n); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return o, fmt.Errorf("%s: %w", f.n, err)
+			}
+			*f.b = b
+		}
+	}
+	return o, nil
+}
+
+// the backend of /solve for the options, as the command line picks it
+func (o options) solver() (solve.Solver, error) {
+	switch {
+	case o.disable != 0 && o.backend != "auto" && o.backend != "logic":
+		return nil, fmt.Errorf("solver %q doesn't use techniques", o.backend)
+	case o.disable != 0 && o.count:
+		return solve.UniqueLogic{Disabled: o.disable}, nil
+	case o.disable != 0:
+		return solve.Logic{Disabled: o.disable}, nil
+	case o.backend != "auto":
+		return solve.Solvers[o.backend], nil
+	}
+	return solve.Auto(solve.Need{Explain: o.trace, Count: o.count}), nil
+}
+
+// the key of the answer of the request r with options o for b
+func answerKeyOf(r *http.Request, o options, b *board.Board) answerKey {
+	k := answerKey{path: r.URL.Path, options: o, values: b.Values(), cages: strings.Join(formats.CageLines(b), "\n")}
+	k.options.timeout = 0
+	for ix := range k.marks {
+		k.marks[ix] = b.Cell(ix).Mask()
+	}
+	return k
+}
+
+// the answer remembered for k
+func (sv *server) answer(k answerKey) (any, bool) {
+	if sv.answers == nil {
+		return nil, false
+	}
+	return sv.answers.Get(k)
+}
+
+// remembers the answer v for k
+func (sv *server) remember(k answerKey, v any) {
+	if sv.answers != nil {
+		sv.answers.Put(k, v)
+	}
+}
+
+// the context of the request r, limited by the timeout of o
+func (o options) context(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), o.timeout)
+}
+
+// the board in the body of r, ready for solving, and the board as the user sent it
+//
+// the body is a board in json, as board.MarshalJSON writes it, or a puzzle in any format of the command line. The
+// candidates of a sukaku are kept, the pencil marks of a board in json are kept as long as they leave the solution, and
+// the others are worked out from the values.
+func (sv *server) board(ctx context.Context, r *http.Request) (board.Board, board.Board, error) {
+	in, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequest))
+	if err != nil {
+		return board.Board{}, board.Board{}, err
+	}
+	p := strings.TrimSpace(string(in))
+
+	b := board.New(sv.layout)
+	m := board.RecomputeMarks
+	switch {
+	case p == "":
+		return board.Board{}, board.Board{}, errors.New("no puzzle in the request")
+	case strings.HasPrefix(p, "{"):
+		if err := json.Unmarshal([]byte(p), &b); err != nil {
+			return b, b, err
+		}
+		wb, err := warmMarks(ctx, b)
+		return wb, b, err
+	case formats.IsSukaku(p):
+		m = board.TrustMarks
+		fallthrough
+	default:
+		b, err = parsePuzzle(sv.layout, p)
+	}
+	if err == nil {
+		err = b.Warm(m)
+	}
+	return b, b, err
+}
+
+// b warmed with its pencil marks if they leave the solution of its values, with the candidates of the values otherwise
+//
+// marks that eliminate a digit of the solution, or a puzzle without a single solution to check them against, would
+// leave the solver stuck on a mistake of the user
+func warmMarks(ctx context.Context, b board.Board) (board.Board, error) {
+	rb := b
+	if err := rb.Warm(board.RecomputeMarks); err != nil {
+		return rb, err
+	}
+	if b.Warm(board.TrustMarks) != nil {
+		return rb, nil
+	}
+	res, err := solve.DLX{Limit: 2}.Solve(ctx, &rb)
+	if err != nil || res.Status != solve.Solved {
+		return rb, err
+	}
+	for ix, v := range res.Solution.Values() {
+		if c := b.Cell(ix); c.IsEmpty() && !c.IsPossible(v) {
+			return rb, nil
+		}
+	}
+	return b, nil
+}
+
+// the singles on the candidates of b that aren't disabled, the ones the pencil marks of user show too first
+//
+// a user sees a naked single with a single mark left in the cell, and a hidden single with the only mark of the digit
+// in the house
+func hints(b, user *board.Board, disabled solve.TechniqueSet) []solve.Step {
+	l := b.Layout()
+	var hs []solve.Step
+	for ix := range 9 * 9 {
+		if c := b.Cell(ix); !disabled.Has(solve.NakedSingle) && c.IsEmpty() && c.IsSingle() {
+			hs = append(hs, solve.Step{Technique: solve.NakedSingle, Coord: coord.Itoc(ix), Value: c.FirstPossibility()})
+		}
+	}
+	for v := cell.ValT(1); v <= 9 && !disabled.Has(solve.HiddenSingle); v++ {
+		for h, s := range l.HouseSets() {
+			s = b.Positions(v).And(s)
+			if s.IsSingle() && (disabled.Has(solve.NakedSingle) || !b.Cell(s.First()).IsSingle()) {
+				hs = append(hs, solve.Step{Technique: solve.HiddenSingle, Coord: coord.Itoc(s.First()), Value: v,
+					House: l.House(h)})
+			}
+		}
+	}
+
+	var seen, rest []solve.Step
+	for _, st := range hs {
+		bb := *b
+		bb.Fill(st.Coord, st.Value)
+		st.Hash = bb.Hash()
+
+		c := user.At(st.Coord)
+		switch {
+		case st.Technique == solve.NakedSingle && c.IsEmpty() && c.IsSingle() && c.IsPossible(st.Value),
+			st.Technique == solve.HiddenSingle && user.Positions(st.Value).And(l.HouseSet(st.House.ID)).IsSingle():
+			seen = append(seen, st)
+		default:
+			rest = append(rest, st)
+		}
+	}
+	return append(seen, rest...)
+}
+
+// writes v as the json answer with the http status code
+func reply(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// answers with err, a bad request unless the request ran out of time or was cancelled
+func fail(w http.ResponseWriter, err error) {
+	code := http.StatusBadRequest
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || errors.Is(err, errMemory) {
+		code = http.StatusServiceUnavailable
+	}
+	reply(w, code, jsonResult{Error: err.Error()})
+}
+
+func (sv *server) solve(w http.ResponseWriter, r *http.Request) {
+	o, err := sv.options(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	s, err := o.solver()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	ctx, cancel := o.context(r)
+	defer cancel()
+
+	b, _, err := sv.board(ctx, r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	k := answerKeyOf(r, o, &b)
+	if v, ok := sv.answer(k); ok {
+		reply(w, http.StatusOK, v)
+		return
+	}
+	res, err := s.Solve(ctx, &b)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	sr := solveResult{jsonResult: jsonResult{Puzzle: b.Line(), Status: res.Status.String()}}
+	if res.Status == solve.Solved {
+		sr.Solution = res.Solution.Line()
+	}
+	if o.trace {
+		d := formats.NewTraceDocument(b, res)
+		sr.Trace = &d
+	}
+	sv.remember(k, sr)
+	reply(w, http.StatusOK, sr)
+}
+
+func (sv *server) rate(w http.ResponseWriter, r *http.Request) {
+	o, err := sv.options(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	ctx, cancel := o.context(r)
+	defer cancel()
+
+	b, _, err := sv.board(ctx, r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	k := answerKeyOf(r, o, &b)
+	if v, ok := sv.answer(k); ok {
+		reply(w, http.StatusOK, v)
+		return
+	}
+	rt, err := rate.RateWithout(ctx, &b, o.disable)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	jr := jsonResult{Puzzle: b.Line(), Status: rt.Status.String(), Rating: &rt}
+	sv.remember(k, jr)
+	reply(w, http.StatusOK, jr)
+}
+
+func (sv *server) hint(w http.ResponseWriter, r *http.Request) {
+	o, err := sv.options(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	ctx, cancel := o.context(r)
+	defer cancel()
+
+	b, user, err := sv.board(ctx, r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	res, err := solve.UniqueLogic{Disabled: o.disable}.Solve(ctx, &b)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	hr := hintResult{jsonResult: jsonResult{Puzzle: b.Line(), Status: res.Status.String()}}
+	if res.Status == solve.Solved && len(res.Trace) > 0 {
+		st := res.Trace[0]
+		if hs := hints(&b, &user, o.disable); len(hs) > 0 {
+			st = hs[0]
+		}
+		if st.Technique == solve.Guess {
+			hr.Status = solve.Stuck.String()
+		} else {
+			hr.Hint = st.String()
+			ts := formats.NewTraceStep(b, st)
+			hr.Step = &ts
+		}
+	}
+	reply(w, http.StatusOK, hr)
+}
+
+func (sv *server) generate(w http.ResponseWriter, r *http.Request) {
+	o, err := sv.options(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	ctx, cancel := o.context(r)
+	defer cancel()
+
+	req := generateRequest{}
+	in, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequest))
+	if err == nil && strings.TrimSpace(string(in)) != "" {
+		err = json.Unmarshal(in, &req)
+	}
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	next, err := generator(ctx, sv.bank, sv.variant, sv.layout, sv.sizes)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	jr := jsonResult{Status: solve.Solved.String()}
+	var p, s board.Board
+	if req.Difficulty == nil {
+		p, s, err = next(rand.New(rand.NewSource(seed)), &gen.Scratch{})
+	} else {
+		jr.Rating = new(rate.Rating)
+		p, s, *jr.Rating, err = gen.GenerateRatedParallel(ctx, *req.Difficulty, generateTries, sv.workers, seed, next)
+	}
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	jr.Puzzle, jr.Cages, jr.Solution = p.Line(), formats.CageLines(&p), s.Line()
+	reply(w, http.StatusOK, jr)
+}
@@ -0,0 +1,31 @@
+//go:build !lowmem
+
+package solve
+
+// solving backends by name, each counting solutions up to 2 so that a puzzle with more than one is told apart
+var Solvers = map[string]Solver{
+	"logic":   UniqueLogic{},
+	"dlx":     DLX{Limit: 2},
+	"compact": Compact{Limit: 2},
+}
+
+// picks the backend for n
+//
+// the logic solver fills cells the way a human would, dancing links is faster and can count solutions. Build with the
+// lowmem tag for the compact solver instead.
+//
+// there is no SAT backend. The exact cover search of dancing links already propagates the constraints a SAT solver
+// would on the cell, digit and house clauses, the compact solver covers the layouts with other rules, and cage sums
+// have no small CNF encoding. It would add a dependency or a solver of its own without a puzzle it solves faster.
+func Auto(n Need) Solver {
+	switch {
+	case n.Explain && n.Count:
+		return UniqueLogic{}
+	case n.Explain:
+		return Logic{}
+	case n.Count:
+		return DLX{Limit: 2}
+	default:
+		return DLX{Limit: 1}
+	}
+}
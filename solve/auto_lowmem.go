@@ -0,0 +1,19 @@
+//go:build lowmem
+
+package solve
+
+// solving backends by name, the compact solver only counting solutions up to 2, build without the lowmem tag for the
+// others
+var Solvers = map[string]Solver{
+	"compact": Compact{Limit: 2},
+}
+
+// picks the compact solver for n, counting solutions if n asks for it
+//
+// the compact solver keeps no trace, so the solving path isn't explained even if n asks for it
+func Auto(n Need) Solver {
+	if n.Count {
+		return Compact{Limit: 2}
+	}
+	return Compact{Limit: 1}
+}
@@ -0,0 +1,30 @@
+package solve
+
+import (
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+)
+
+// the single cell backdoors of b: the empty cells that, filled with their value in solution, leave a board that
+// singles alone solve
+//
+// the cells are in index order. Without a backdoor cell solving b needs at least two guesses, if singles solve b
+// already every empty cell is a backdoor.
+func Backdoors(b, solution *board.Board) []coord.Coord {
+	r := []coord.Coord{}
+	sv := solution.Values()
+
+	for ix, v := range b.Values() {
+		if v != 0 {
+			continue
+		}
+		c := coord.Itoc(ix)
+		bb := *b
+		bb.Fill(c, sv[ix])
+		Singles(&bb, nil)
+		if bb.Solved() {
+			r = append(r, c)
+		}
+	}
+	return r
+}
@@ -0,0 +1,78 @@
+package solve
+
+import (
+	"math/bits"
+
+	"github.com/phaul/sudoku/cell"
+
+	"github.com/phaul/sudoku/board"
+)
+
+// the empty cells of a killer cage, walked for the candidates that can add up to the sum of the cage
+type cageWalk struct {
+	masks   [9]uint16     // candidates of the empty cells
+	n       int           // number of empty cells
+	support [9]uint16     // candidates of the empty cells in a combination adding up to the sum
+	memo    [10][512]int8 // by cell and digits used so far: 0 not walked yet, 1 adds up, -1 doesn't
+}
+
+// can the empty cells from i on take distinct digits not in u adding up to rest, marking the digits that do in support
+//
+// the digits used decide rest, so a cell and u are enough to look up a walk done before
+func (w *cageWalk) walk(i int, u uint16, rest int) bool {
+	if i == w.n {
+		return rest == 0
+	}
+	if m := w.memo[i][u]; m != 0 {
+		return m > 0
+	}
+	ok := false
+	for m := w.masks[i] &^ u; m != 0; m &= m - 1 {
+		d := bits.TrailingZeros16(m) + 1
+		if d > rest {
+			break
+		}
+		if w.walk(i+1, u|1<<(d-1), rest-d) {
+			w.support[i] |= 1 << (d - 1)
+			ok = true
+		}
+	}
+	w.memo[i][u] = -1
+	if ok {
+		w.memo[i][u] = 1
+	}
+	return ok
+}
+
+// drops the candidates of the cells of killer cages that no combination of distinct digits adding up to the sum of the
+// cage uses
+//
+// returns true if any were dropped
+func cageSums(b *board.Board) bool {
+	r := false
+	l := b.Layout()
+	for k, cg := range l.Cages() {
+		w := cageWalk{}
+		ixs := [9]int{}
+		rest := cg.Sum
+		for _, ix := range l.CageIndices(k) {
+			if c := b.Cell(ix); c.IsEmpty() {
+				ixs[w.n], w.masks[w.n] = ix, c.Mask()
+				w.n++
+			} else {
+				rest -= int(c.Value)
+			}
+		}
+		if w.n == 0 {
+			continue
+		}
+		w.walk(0, 0, rest)
+		for i := range w.n {
+			for m := w.masks[i] &^ w.support[i]; m != 0; m &= m - 1 {
+				b.Drop(ixs[i], cell.ValT(bits.TrailingZeros16(m)+1))
+				r = true
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,77 @@
+package solve
+
+import (
+	"context"
+	"math/bits"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// a guess of the compact solver: the board it's taken on, the cell and the candidates of the cell not tried yet
+type compactFrame struct {
+	board board.Board
+	c     coord.Coord
+	left  uint16
+}
+
+// singles and backtracking on the candidate masks of the board, for constrained targets
+//
+// the search works in a fixed stack of a board per guess, at most one per cell, and allocates nothing else: there is no
+// dancing links matrix, no trace and no cache. It counts solutions up to Limit like DLX, a Limit under 1 is 1. Build
+// with the lowmem tag for Auto and Solvers to pick it over the other backends.
+type Compact struct{ Limit int }
+
+func (s Compact) Solve(ctx context.Context, b *board.Board) (Result, error) {
+	start := time.Now()
+	r := Result{Status: Unsolvable}
+	var st [9*9 + 1]compactFrame
+
+	n, top := 0, 0
+	st[0].board = *b
+	for top >= 0 {
+		if ctx.Err() != nil {
+			return aborted(ctx, Result{Stats: Stats{Nodes: r.Stats.Nodes, Duration: time.Since(start)}})
+		}
+		f := &st[top]
+		r.Stats.Nodes++
+		Singles(&f.board, nil)
+		switch {
+		case f.board.Solved():
+			n++
+			if n == 1 {
+				r.Status, r.Solution = Solved, f.board
+			}
+			f.left = 0
+		case f.board.Contradicts():
+			f.left = 0
+		default:
+			f.c = f.board.Fewest()
+			f.left = f.board.At(f.c).Mask()
+		}
+		if n >= max(s.Limit, 1) {
+			break
+		}
+
+		for top >= 0 && st[top].left == 0 {
+			top--
+		}
+		if top < 0 {
+			break
+		}
+		f = &st[top]
+		v := cell.ValT(bits.TrailingZeros16(f.left) + 1)
+		f.left &= f.left - 1
+		st[top+1].board = f.board
+		st[top+1].board.Fill(f.c, v)
+		top++
+	}
+
+	if n > 1 {
+		r.Status = Multiple
+	}
+	r.Stats.Duration = time.Since(start)
+	return r, nil
+}
@@ -0,0 +1,160 @@
+package solve
+
+import (
+	"context"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// exact cover matrix for Knuth's dancing links
+//
+// nodes are addressed by index, 0 is the root and 1..columns are the column headers
+type dlx struct {
+	l, r, u, d []int // links of the nodes
+	col        []int // column header of the nodes
+	row        []int // candidate of the nodes, cell index * 9 + digit - 1
+	size       []int // number of nodes in the columns
+	solution   []int // candidates of the partial solution during search
+	first      []int // candidates of the first solution found
+	steps      int   // search steps, for cancellation checks
+}
+
+// exact cover matrix for b. There is a column for each cell and for each digit in each house, and a row for each
+// candidate.
+func newDLX(b *board.Board) *dlx {
+	of := [9 * 9][]int{} // houses of cells
+	hs := b.Layout().Houses()
+	h := 0
+	for hs.Next() {
+		r := hs.Value().(coord.Iterator)
+		for r.Next() {
+			ix := coord.Ctoi(r.Value().(coord.Coord))
+			of[ix] = append(of[ix], h)
+		}
+		h++
+	}
+
+	n := 9*9 + h*9
+	x := dlx{size: make([]int, n+1)}
+	for i := 0; i <= n; i++ {
+		x.l = append(x.l, i-1)
+		x.r = append(x.r, i+1)
+		x.u = append(x.u, i)
+		x.d = append(x.d, i)
+		x.col = append(x.col, i)
+		x.row = append(x.row, -1)
+	}
+	x.l[0] = n
+	x.r[n] = 0
+
+	for ix := range 9 * 9 {
+		c := b.Cell(ix)
+		for v := cell.ValT(1); v <= 9; v++ {
+			if c.Value == v || (c.IsEmpty() && c.IsPossible(v)) {
+				cols := []int{ix}
+				for _, h := range of[ix] {
+					cols = append(cols, 9*9+h*9+int(v)-1)
+				}
+				x.add(ix*9+int(v)-1, cols)
+			}
+		}
+	}
+	return &x
+}
+
+// adds a row for candidate with nodes in cols
+func (x *dlx) add(candidate int, cols []int) {
+	first := -1
+
+	for _, c := range cols {
+		hd := c + 1
+		n := len(x.l)
+		x.col = append(x.col, hd)
+		x.row = append(x.row, candidate)
+		x.u = append(x.u, x.u[hd])
+		x.d = append(x.d, hd)
+		x.d[x.u[hd]] = n
+		x.u[hd] = n
+		x.size[hd]++
+		if first < 0 {
+			first = n
+			x.l = append(x.l, n)
+			x.r = append(x.r, n)
+		} else {
+			x.l = append(x.l, x.l[first])
+			x.r = append(x.r, first)
+			x.r[x.l[first]] = n
+			x.l[first] = n
+		}
+	}
+}
+
+func (x *dlx) cover(c int) {
+	x.r[x.l[c]] = x.r[c]
+	x.l[x.r[c]] = x.l[c]
+	for i := x.d[c]; i != c; i = x.d[i] {
+		for j := x.r[i]; j != i; j = x.r[j] {
+			x.d[x.u[j]] = x.d[j]
+			x.u[x.d[j]] = x.u[j]
+			x.size[x.col[j]]--
+		}
+	}
+}
+
+func (x *dlx) uncover(c int) {
+	for i := x.u[c]; i != c; i = x.u[i] {
+		for j := x.l[i]; j != i; j = x.l[j] {
+			x.size[x.col[j]]++
+			x.d[x.u[j]] = j
+			x.u[x.d[j]] = j
+		}
+	}
+	x.r[x.l[c]] = c
+	x.l[x.r[c]] = c
+}
+
+// counts exact covers up to limit, remembering the first one found
+func (x *dlx) search(ctx context.Context, limit int) (int, error) {
+	if x.r[0] == 0 {
+		if x.first == nil {
+			x.first = append([]int{}, x.solution...)
+		}
+		return 1, nil
+	}
+	if x.steps++; x.steps%1024 == 0 && ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	// the column with the fewest rows
+	c := x.r[0]
+	for j := x.r[c]; j != 0; j = x.r[j] {
+		if x.size[j] < x.size[c] {
+			c = j
+		}
+	}
+
+	n := 0
+	x.cover(c)
+	for i := x.d[c]; i != c && n < limit; i = x.d[i] {
+		x.solution = append(x.solution, x.row[i])
+		for j := x.r[i]; j != i; j = x.r[j] {
+			x.cover(x.col[j])
+		}
+
+		m, err := x.search(ctx, limit-n)
+		n += m
+
+		for j := x.l[i]; j != i; j = x.l[j] {
+			x.uncover(x.col[j])
+		}
+		x.solution = x.solution[:len(x.solution)-1]
+		if err != nil {
+			x.uncover(c)
+			return n, err
+		}
+	}
+	x.uncover(c)
+	return n, nil
+}
@@ -0,0 +1,58 @@
+package solve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/phaul/sudoku/board"
+)
+
+var (
+	ErrUnsolvable = errors.New("puzzle is unsolvable")             // the puzzle has no solution
+	ErrMultiple   = errors.New("puzzle has multiple solutions")    // the puzzle has more than one solution
+	ErrTimeout    = errors.New("timed out")                        // the deadline passed before finishing
+	ErrAborted    = errors.New("aborted before finishing solving") // ctx was cancelled
+	ErrStuck      = errors.New("enabled techniques ran out")       // the puzzle needs a disabled technique
+	ErrDiverged   = errors.New("replay diverged from the trace")   // a replayed step left a board with another hash
+)
+
+// a puzzle that repeats a value in a house, for errors.As next to the other errors of the package
+type ErrInvalidPuzzle = board.InvalidPuzzleError
+
+// the error for a done ctx, ErrTimeout if its deadline passed, the cause if ctx was cancelled with one
+func abort(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+	}
+	return context.Cause(ctx)
+}
+
+// r aborted because ctx is done, with the error of the abort
+func aborted(ctx context.Context, r Result) (Result, error) {
+	r.Status, r.Cause = Aborted, abort(ctx)
+	return r, r.Cause
+}
+
+// the error for the status of r, nil if it was solved
+//
+// an aborted solve is ErrTimeout if its deadline passed, and ErrAborted wrapping the cause otherwise
+func (r Result) Err() error {
+	switch r.Status {
+	case Unsolvable:
+		return ErrUnsolvable
+	case Multiple:
+		return ErrMultiple
+	case Aborted:
+		switch {
+		case r.Cause == nil:
+			return ErrAborted
+		case errors.Is(r.Cause, ErrTimeout):
+			return r.Cause
+		}
+		return fmt.Errorf("%w: %w", ErrAborted, r.Cause)
+	case Stuck:
+		return ErrStuck
+	}
+	return nil
+}
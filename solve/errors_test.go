@@ -0,0 +1,53 @@
+package solve_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/solve"
+)
+
+// the error of an aborted result tells a deadline from a cancellation, and its cause is the error of Solve
+func TestAbortedErr(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, s := range map[string]solve.Solver{
+		"logic":   solve.Logic{},
+		"compact": solve.Compact{Limit: 2},
+	} {
+		for _, c := range []struct {
+			ctx       context.Context
+			want, not error
+		}{{expired, solve.ErrTimeout, solve.ErrAborted}, {cancelled, solve.ErrAborted, solve.ErrTimeout}} {
+			b := board.New(coord.Standard)
+			r, err := s.Solve(c.ctx, &b)
+			if r.Status != solve.Aborted {
+				t.Errorf("%s: status %v, want %v", name, r.Status, solve.Aborted)
+				continue
+			}
+			if err == nil || err != r.Cause {
+				t.Errorf("%s: error %v, want the cause %v", name, err, r.Cause)
+			}
+			if e := r.Err(); !errors.Is(e, c.want) || errors.Is(e, c.not) {
+				t.Errorf("%s: error %v, want %v", name, e, c.want)
+			}
+		}
+	}
+}
+
+func TestInvalidPuzzleAs(t *testing.T) {
+	b := board.New(coord.Standard)
+	b.Give(coord.Itoc(0), 5)
+	b.Give(coord.Itoc(8), 5)
+	var e *solve.ErrInvalidPuzzle
+	if err := b.Validate(); !errors.As(err, &e) || e.Value != 5 {
+		t.Errorf("error %v, want an *ErrInvalidPuzzle repeating 5", err)
+	}
+}
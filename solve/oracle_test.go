@@ -0,0 +1,237 @@
+package solve
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// does d fit in the cell at ix of the values v of a board of layout l, without repeating in a house or a linked cell
+func fits(l coord.Layout, v *[9 * 9]cell.ValT, ix int, d cell.ValT) bool {
+	for _, h := range l.HousesOf(ix) {
+		for _, p := range l.HouseIndices()[h] {
+			if p != ix && v[p] == d {
+				return false
+			}
+		}
+	}
+	for _, p := range l.Links(ix) {
+		if v[p] == d {
+			return false
+		}
+	}
+	return true
+}
+
+// do the killer cages of layout l add up to their sums in the full values v
+func addsUp(l coord.Layout, v *[9 * 9]cell.ValT) bool {
+	for k, cg := range l.Cages() {
+		sum := 0
+		for _, ix := range l.CageIndices(k) {
+			sum += int(v[ix])
+		}
+		if sum != cg.Sum {
+			return false
+		}
+	}
+	return true
+}
+
+// the number of solutions of b and the first one, found by trying every digit in every empty cell in turn
+//
+// the oracle of the solvers: it only looks at the values of b and the rules of its layout, none of the candidates,
+// the propagation or the search the solvers share. It's exhaustive, so it's for boards with few empty cells.
+func enumerate(b *board.Board) (n int, first [9 * 9]cell.ValT) {
+	l := b.Layout()
+	v := b.Values()
+	empty := []int{}
+	for ix, d := range v {
+		switch {
+		case d == 0:
+			empty = append(empty, ix)
+		case !fits(l, &v, ix, d):
+			return 0, first
+		}
+	}
+
+	var try func(i int)
+	try = func(i int) {
+		if i == len(empty) {
+			if !addsUp(l, &v) {
+				return
+			}
+			if n == 0 {
+				first = v
+			}
+			n++
+			return
+		}
+		ix := empty[i]
+		for d := cell.ValT(1); d <= 9; d++ {
+			if fits(l, &v, ix, d) {
+				v[ix] = d
+				try(i + 1)
+				v[ix] = 0
+			}
+		}
+	}
+	try(0)
+	return n, first
+}
+
+// an empty killer board of dominoes along the rows of the solution of the first sample
+func killerBoard(t testing.TB) board.Board {
+	t.Helper()
+	b := sampleBoards(t)[0]
+	r, err := DLX{Limit: 1}.Solve(context.Background(), &b)
+	if err != nil || r.Status != Solved {
+		t.Fatalf("%s: %v %v", b.Line(), r.Status, err)
+	}
+	cages := []coord.Cage{}
+	for y := range 9 {
+		for x := 0; x < 9; x += 2 {
+			cg := coord.Cage{}
+			for ix := y*9 + x; ix <= min(y*9+x+1, y*9+8); ix++ {
+				cg.Cells = append(cg.Cells, coord.Itoc(ix))
+				cg.Sum += int(r.Solution.Cell(ix).Value)
+			}
+			cages = append(cages, cg)
+		}
+	}
+	l, err := coord.Killer(cages...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return board.New(l)
+}
+
+// boards of the solutions of the samples with a few cells emptied, some with a wrong digit in an emptied cell
+//
+// the boards have up to 29 empty cells, few enough for enumerate, and come out unique, multiple and unsolvable.
+func oracleBoards(t testing.TB) []board.Board {
+	t.Helper()
+	rng := rand.New(rand.NewSource(2))
+	bs := []board.Board{}
+	// empty anti-knight and killer boards stand in for the samples of the layouts with rules beyond the houses
+	for _, b := range append(sampleBoards(t), board.New(coord.AntiKnight), killerBoard(t)) {
+		r, err := DLX{Limit: 1}.Solve(context.Background(), &b)
+		if err != nil || r.Status != Solved {
+			t.Fatalf("%s: %v %v", b.Line(), r.Status, err)
+		}
+		for range 20 {
+			v := r.Solution.Values()
+			blank := rng.Perm(9 * 9)[:5+rng.Intn(25)]
+			for _, ix := range blank {
+				v[ix] = 0
+			}
+			// on half the boards, a digit in an emptied cell that doesn't repeat in a house but isn't the one of the
+			// solution
+			for _, ix := range blank[:rng.Intn(2)*len(blank)] {
+				d := cell.ValT(rng.Intn(9) + 1)
+				if d != r.Solution.Cell(ix).Value && fits(b.Layout(), &v, ix, d) {
+					v[ix] = d
+					break
+				}
+			}
+			bs = append(bs, board.FromValues(b.Layout(), v))
+		}
+	}
+	return bs
+}
+
+// is s a full board of the values of b, with no digit repeated in a house and the cages adding up
+func solves(b *board.Board, s board.Board) bool {
+	v, sv := b.Values(), s.Values()
+	for ix, d := range sv {
+		if d == 0 || v[ix] != 0 && v[ix] != d || !fits(b.Layout(), &sv, ix, d) {
+			return false
+		}
+	}
+	return addsUp(b.Layout(), &sv)
+}
+
+func TestSolversAgreeWithEnumeration(t *testing.T) {
+	ctx := context.Background()
+	counted := map[Status]int{}
+	for _, b := range oracleBoards(t) {
+		n, first := enumerate(&b)
+		want := Solved
+		switch {
+		case n == 0:
+			want = Unsolvable
+		case n > 1:
+			want = Multiple
+		}
+		counted[want]++
+
+		// the exact cover matrix only has the houses
+		if p := b; b.Layout().HousesOnly() {
+			if got, err := newDLX(&p).search(ctx, 1<<30); err != nil || got != n {
+				t.Errorf("%s: dancing links counted %d solutions, %v, enumerating %d", b.Line(), got, err, n)
+			}
+		}
+
+		for name, s := range map[string]Solver{
+			"dlx":      DLX{Limit: 2},
+			"compact":  Compact{Limit: 2},
+			"parallel": Parallel{Workers: 2, Limit: 2},
+			"unique":   UniqueLogic{},
+		} {
+			p := b
+			r, err := s.Solve(ctx, &p)
+			switch {
+			case err != nil:
+				t.Errorf("%s: %s: %v", b.Line(), name, err)
+			case r.Status != want:
+				t.Errorf("%s: %s: %v, enumerating %d solutions", b.Line(), name, r.Status, n)
+			case want == Solved && r.Solution.Values() != first:
+				t.Errorf("%s: %s solved it as %s", b.Line(), name, r.Solution.Line())
+			}
+		}
+
+		// the logic solver doesn't count, it only has to find a solution if there is any
+		p := b
+		r, err := Logic{}.Solve(ctx, &p)
+		switch {
+		case err != nil:
+			t.Errorf("%s: logic: %v", b.Line(), err)
+		case n == 0 && r.Status != Unsolvable:
+			t.Errorf("%s: logic: %v, enumerating no solutions", b.Line(), r.Status)
+		case n > 0 && (r.Status != Solved || !solves(&b, r.Solution)):
+			t.Errorf("%s: logic: %v %s, enumerating %d solutions", b.Line(), r.Status, r.Solution.Line(), n)
+		}
+	}
+	for _, s := range []Status{Solved, Unsolvable, Multiple} {
+		if counted[s] == 0 {
+			t.Errorf("no %v boards", s)
+		}
+	}
+	t.Log(counted)
+}
+
+func TestSolversAgreeOnSamples(t *testing.T) {
+	ctx := context.Background()
+	for _, b := range sampleBoards(t) {
+		p := b
+		want, err := DLX{Limit: 2}.Solve(ctx, &p)
+		if err != nil || want.Status != Solved {
+			t.Fatalf("%s: dancing links: %v %v", b.Line(), want.Status, err)
+		}
+		for name, s := range map[string]Solver{
+			"logic":    Logic{},
+			"unique":   UniqueLogic{},
+			"compact":  Compact{Limit: 2},
+			"parallel": Parallel{Workers: 2, Limit: 2},
+		} {
+			p := b
+			r, err := s.Solve(ctx, &p)
+			if err != nil || r.Status != Solved || r.Solution.Values() != want.Solution.Values() {
+				t.Errorf("%s: %s: %v %v %s", b.Line(), name, r.Status, err, r.Solution.Line())
+			}
+		}
+	}
+}
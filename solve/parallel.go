@@ -0,0 +1,123 @@
+package solve
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+)
+
+// the solutions counted by the workers of a parallel solve reached the limit
+var errDecided = errors.New("puzzle decided")
+
+// dancing links on n workers, counting solutions up to limit
+//
+// the search is split into subtrees by guessing the cells with the fewest candidates. The workers share a cancellation
+// signal, once the first solution is found, or with a limit over 1 a second one disproves uniqueness, the workers still
+// searching stop.
+type Parallel struct {
+	Workers int // 0 for one per CPU
+	Limit   int
+}
+
+func (s Parallel) Solve(ctx context.Context, b *board.Board) (Result, error) {
+	start := time.Now()
+	n := s.Workers
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	limit := max(s.Limit, 1)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	bs := split(*b, n)
+	work := make(chan int)
+	mu := sync.Mutex{}
+	r := Result{}
+	found := 0
+
+	wg := sync.WaitGroup{}
+	for range min(n, len(bs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				sr, err := DLX{Limit: limit}.Solve(ctx, &bs[i])
+
+				mu.Lock()
+				r.Stats.Nodes += sr.Stats.Nodes
+				if err == nil && sr.Status != Unsolvable {
+					if found == 0 {
+						r.Solution = sr.Solution
+					}
+					found++
+					if sr.Status == Multiple {
+						found++
+					}
+					if found >= limit {
+						cancel(errDecided)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range bs {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	r.Stats.Duration = time.Since(start)
+	switch {
+	case found >= limit && limit > 1:
+		r.Status = Multiple
+	case found > 0 && (found >= limit || ctx.Err() == nil):
+		r.Status = Solved
+	case ctx.Err() != nil:
+		return aborted(ctx, r)
+	default:
+		r.Status = Unsolvable
+	}
+	return r, nil
+}
+
+// subtrees of the search of b for n workers
+//
+// the boards are expanded breadth first by filling in every candidate of the cell with the fewest, until there are at
+// least n of them or all of them are full. boards with an empty cell without candidates are dropped.
+func split(b board.Board, n int) []board.Board {
+	bs := []board.Board{b}
+
+	for full := 0; len(bs) < n && full < len(bs); {
+		x := bs[0]
+		bs = bs[1:]
+		if x.Solved() {
+			bs = append(bs, x)
+			full++
+			continue
+		}
+
+		full = 0
+		c := x.Fewest()
+		if x.At(c).PossibilityCount() == 0 {
+			continue
+		}
+		for i := x.At(c).Possibilities(); i.Next(); {
+			bb := x
+			bb.Fill(c, i.Value())
+			bs = append(bs, bb)
+		}
+	}
+	return bs
+}
@@ -0,0 +1,28 @@
+package solve
+
+import "fmt"
+
+// tunable parameters of the iterative deepening search of the logic solver
+type Params struct {
+	Depth      int  `json:"depth"`      // guesses allowed in the first iteration
+	Step       int  `json:"step"`       // guesses added when an iteration fails, restarting the search
+	WidthDiv   int  `json:"width_div"`  // the widest cell guessed is the guess limit divided by this
+	MinWidth   int  `json:"min_width"`  // the widest cell guessed in any iteration
+	Descending bool `json:"descending"` // try the candidates of a guessed cell from 9 down to 1
+}
+
+// search parameters used when no profile is loaded
+var DefaultParams = Params{Depth: 3, Step: 1, WidthDiv: 3, MinWidth: 2}
+
+// search parameters of the logic solver, DefaultParams unless a profile is loaded
+//
+// Profile is read by every solve, it must not be changed while solving
+var Profile = DefaultParams
+
+// checks that p describes a search that terminates
+func (p Params) Validate() error {
+	if p.Depth < 1 || p.Step < 1 || p.WidthDiv < 1 || p.MinWidth < 1 {
+		return fmt.Errorf("invalid search parameters %+v, all have to be positive", p)
+	}
+	return nil
+}
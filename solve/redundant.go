@@ -0,0 +1,53 @@
+package solve
+
+import (
+	"context"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// the givens of b that can be taken out with the solution staying unique, in index order
+//
+// each given is checked on its own, taking out two redundant givens together can make the solution ambiguous. A given
+// is redundant when the puzzle without it has no solution with a different value in its cell, so a check is a search
+// for a single solution with the value ruled out, not a count of the solutions. the givens of b have to have a unique
+// solution, ErrUnsolvable or ErrMultiple otherwise.
+func RedundantClues(ctx context.Context, b *board.Board) ([]coord.Coord, error) {
+	givens := [9 * 9]cell.ValT{}
+	for ix, v := range b.Values() {
+		if b.IsGiven(coord.Itoc(ix)) {
+			givens[ix] = v
+		}
+	}
+
+	p := board.FromValues(b.Layout(), givens)
+	r, err := DLX{Limit: 2}.Solve(ctx, &p)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	cs := []coord.Coord{}
+	for ix, v := range givens {
+		if v == 0 {
+			continue
+		}
+		givens[ix] = 0
+		bb := board.FromValues(b.Layout(), givens)
+		givens[ix] = v
+
+		bb.Drop(ix, v)
+		r, err := DLX{Limit: 1}.Solve(ctx, &bb)
+		if err != nil {
+			return nil, err
+		}
+		if r.Status == Unsolvable {
+			cs = append(cs, coord.Itoc(ix))
+		}
+	}
+	return cs, nil
+}
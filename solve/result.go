@@ -0,0 +1,179 @@
+package solve
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// outcome of a solve
+type Status int
+
+const (
+	Solved     Status = iota // a solution was found
+	Unsolvable               // the puzzle has no solution
+	Multiple                 // the puzzle has more than one solution
+	Aborted                  // the solve was cancelled before it could finish
+	Stuck                    // the enabled techniques ran out before the puzzle was solved
+)
+
+func (s Status) String() string {
+	switch s {
+	case Solved:
+		return "solved"
+	case Unsolvable:
+		return "unsolvable"
+	case Multiple:
+		return "multiple solutions"
+	case Aborted:
+		return "aborted"
+	case Stuck:
+		return "stuck"
+	}
+	return fmt.Sprintf("status(%d)", int(s))
+}
+
+func (s Status) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *Status) UnmarshalText(text []byte) error {
+	for v := Solved; v <= Stuck; v++ {
+		if v.String() == string(text) {
+			*s = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown status %q", text)
+}
+
+// solving technique of a step
+type Technique int
+
+const (
+	NakedSingle  Technique = iota // the only candidate left in a cell
+	HiddenSingle                  // the only place left for a digit in a house
+	Guess                         // a trial in the search
+)
+
+func (t Technique) String() string {
+	switch t {
+	case NakedSingle:
+		return "naked single"
+	case HiddenSingle:
+		return "hidden single"
+	case Guess:
+		return "guess"
+	}
+	return fmt.Sprintf("technique(%d)", int(t))
+}
+
+func (t Technique) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+func (t *Technique) UnmarshalText(text []byte) error {
+	for v := NakedSingle; v <= Guess; v++ {
+		if v.String() == string(text) {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown technique %q", text)
+}
+
+// a set of techniques
+type TechniqueSet uint
+
+// the set of ts
+func TechniquesOf(ts ...Technique) TechniqueSet {
+	s := TechniqueSet(0)
+	for _, t := range ts {
+		s |= 1 << t
+	}
+	return s
+}
+
+// t is in s
+func (s TechniqueSet) Has(t Technique) bool { return s&(1<<t) != 0 }
+
+// techniques by their command line name
+var Techniques = map[string]Technique{
+	"naked-single":  NakedSingle,
+	"hidden-single": HiddenSingle,
+	"guess":         Guess,
+}
+
+// a cell filled by the solver
+type Step struct {
+	Technique Technique
+	Coord     coord.Coord
+	Value     cell.ValT
+	House     coord.House // the house a hidden single is the only place in, the zero House for other techniques
+	Hash      uint64      // hash of the board values after the step, as in board.Hash
+}
+
+func (s Step) String() string {
+	r := fmt.Sprintf("%s: r%dc%d=%d", s.Technique, s.Coord.Y+1, s.Coord.X+1, s.Value)
+	if s.House.Kind != "" {
+		r += " in " + s.House.String()
+	}
+	return r
+}
+
+// steps in the order they were taken
+type Trace []Step
+
+// records s, unless t is nil
+func (t *Trace) add(s Step) {
+	if t != nil {
+		*t = append(*t, s)
+	}
+}
+
+// fills the steps of t on b, checking the hash of the board after every step
+//
+// returns the board after the last step, or an error wrapping ErrDiverged at the first step leaving a board with a
+// different hash than the step recorded, with the board before that step.
+func (t Trace) Replay(b board.Board) (board.Board, error) {
+	for i, st := range t {
+		bb := b
+		bb.Fill(st.Coord, st.Value)
+		if bb.Hash() != st.Hash {
+			return b, fmt.Errorf("%w: step %d, %s", ErrDiverged, i+1, st)
+		}
+		b = bb
+	}
+	return b, nil
+}
+
+// the steps of t after the step that left the board with hash h, false if no step did
+//
+// a replay that got as far as a board with hash h resumes with these steps, one that hasn't started needs all of t.
+func (t Trace) After(h uint64) (Trace, bool) {
+	for i, st := range t {
+		if st.Hash == h {
+			return t[i+1:], true
+		}
+	}
+	return nil, false
+}
+
+// statistics of a solve
+type Stats struct {
+	Nodes    int           // search tree nodes visited
+	Duration time.Duration // wall time of the solve
+
+	Guesses    [9 * 9]int // guesses at each cell by index, taken back ones included, only counted by the logic solver
+	Backtracks [9]int     // guesses of each digit taken back, digit 1 first, only counted by the logic solver
+}
+
+func (s Stats) String() string { return fmt.Sprintf("%d nodes in %v", s.Nodes, s.Duration) }
+
+// outcome of a solve
+type Result struct {
+	Status   Status
+	Solution board.Board // the solution, the first of them for Multiple, or the board the techniques left for Stuck
+	Stats    Stats
+	Trace    Trace // steps leading to the solution, only recorded by the logic solver
+	Cause    error // why an Aborted solve stopped, the error Solve returned with it
+}
@@ -0,0 +1,254 @@
+package solve
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/internal/cqueue"
+)
+
+// look for cells that have a single possibility and fill them, until there are none left
+//
+// the board is scanned once, after that only the peers that lost the digit of a fill are looked at again, so the singles
+// the fills make are filled in the same call without rescanning the board. return true if any were filled or false
+// otherwise
+func singlePossible(b *board.Board, t *Trace) bool {
+	// every cell is queued at most once: a queued cell is either filled or left without candidates when its turn comes
+	var queue [9 * 9]int
+	queued := coord.Set{}
+	n := 0
+	for ix := range queue {
+		if b.Cell(ix).IsSingle() {
+			queue[n] = ix
+			queued.Add(ix)
+			n++
+		}
+	}
+
+	r := false
+	l := b.Layout()
+	for i := 0; i < n; i++ {
+		ix := queue[i]
+		c := b.Cell(ix)
+		// on a board with a contradiction a fill since it was queued can have taken its last candidate
+		if !c.IsSingle() {
+			continue
+		}
+		v := c.FirstPossibility()
+		hit := b.Positions(v).And(l.PeerSet(ix))
+		co := coord.Itoc(ix)
+		b.Fill(co, v)
+		t.add(Step{Technique: NakedSingle, Coord: co, Value: v, Hash: b.Hash()})
+		r = true
+
+		hit = hit.AndNot(queued)
+		for p := hit.First(); p >= 0; p = hit.First() {
+			hit.Remove(p)
+			if b.Cell(p).IsSingle() {
+				queue[n] = p
+				queued.Add(p)
+				n++
+			}
+		}
+	}
+	return r
+}
+
+// find digits that can only go in one place in a house, and fill them in, in a single pass over the digits
+//
+// a digit is a hidden single in a house when its candidate bitboard masked with the house has a single cell
+//
+// returns true if any found
+func onlyPlace(b *board.Board, t *Trace) bool {
+	r := false
+
+	l := b.Layout()
+	for v := cell.ValT(1); v <= 9; v++ {
+		for h, hs := range l.HouseSets() {
+			// an earlier fill of the pass shrinks the bitboard, so it's masked again for every house
+			s := b.Positions(v).And(hs)
+			if !s.IsSingle() {
+				continue
+			}
+			co := coord.Itoc(s.First())
+			b.Fill(co, v)
+			t.add(Step{Technique: HiddenSingle, Coord: co, Value: v, House: l.House(h), Hash: b.Hash()})
+			r = true
+		}
+	}
+
+	return r
+}
+
+// fills naked and hidden singles until there are none left, recording the steps in t unless it's nil
+//
+// on a killer board the candidates that can't add up to the sum of their cage are dropped too
+func Singles(b *board.Board, t *Trace) {
+	singles(b, t, 0)
+}
+
+// Singles without the disabled techniques
+func singles(b *board.Board, t *Trace, disabled TechniqueSet) {
+	for (!disabled.Has(NakedSingle) && singlePossible(b, t)) || (!disabled.Has(HiddenSingle) && onlyPlace(b, t)) ||
+		cageSums(b) {
+	}
+}
+
+// state of an iterative deepening search
+type search struct {
+	ctx      context.Context
+	params   Params
+	maxDepth int   // limits the number of guesses allowed before solve returns with false
+	maxWidth int   // limits where guesses can happen, don't guess a cell if it has more possiblities than maxWidth
+	cut      bool  // maxDepth or maxWidth prevented exploring part of the search space
+	stats    Stats // statistics of the search
+	trace    Trace // steps leading to the current board
+
+	disabled TechniqueSet // techniques the search doesn't use
+	stuck    bool         // singles ran out with guessing disabled
+}
+
+// solving with iterative deepening, using the search parameters of Profile
+//
+// the board is left untouched, the solution is in the result
+func Iterate(ctx context.Context, b *board.Board) Result {
+	return IterateWith(ctx, b, Profile)
+}
+
+// Iterate with the search parameters p
+func IterateWith(ctx context.Context, b *board.Board, p Params) Result {
+	return iterate(ctx, b, p, 0)
+}
+
+// IterateWith without the disabled techniques
+func iterate(ctx context.Context, b *board.Board, p Params, disabled TechniqueSet) Result {
+	s := search{ctx: ctx, params: p, disabled: disabled}
+	start := time.Now()
+
+	for s.maxDepth = p.Depth; ; s.maxDepth += p.Step {
+		s.maxWidth = max(s.maxDepth/p.WidthDiv, p.MinWidth)
+		s.cut = false
+		s.trace = s.trace[:0]
+		bb := *b
+
+		ok := s.solve(&bb, 0)
+		s.stats.Duration = time.Since(start)
+		switch {
+		case ok:
+			return Result{Status: Solved, Solution: bb, Stats: s.stats, Trace: s.trace}
+		case ctx.Err() != nil:
+			return Result{Status: Aborted, Stats: s.stats, Cause: abort(ctx)}
+		case s.stuck:
+			return Result{Status: Stuck, Solution: bb, Stats: s.stats, Trace: s.trace}
+		case !s.cut:
+			// the whole search space was explored
+			return Result{Status: Unsolvable, Stats: s.stats}
+		}
+	}
+}
+
+// tries to do a solve
+// first it fills in what we know for sure
+// then checks if solved or has a contradiction due to incorrect guess
+// then tries the easiest guess
+func (s *search) solve(b *board.Board, depth int) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+	if depth >= s.maxDepth {
+		s.cut = true
+		return false
+	}
+	s.stats.Nodes++
+	singles(b, &s.trace, s.disabled)
+	if b.Solved() {
+		return true
+	}
+	if b.Contradicts() {
+		return false
+	}
+	if s.disabled.Has(Guess) {
+		// without guesses the search never goes deeper than the root, b is where the techniques left it
+		s.stuck = true
+		return false
+	}
+	return s.try(b, depth)
+}
+
+// coordinates to try in the order of least amount of possible candidates to most
+func tries(b *board.Board, maxWidth int) cqueue.Queue {
+	q := cqueue.New()
+	i := coord.All()
+
+	for i.Next() {
+		c := i.Value().(coord.Coord)
+		cell := b.At(c)
+		p := cell.PossibilityCount()
+		if 0 < p && p <= maxWidth {
+			heap.Push(&q, cqueue.PrioCoord{Count: p, Coord: c})
+		}
+	}
+
+	return q
+}
+
+func (s *search) try(b *board.Board, depth int) bool {
+	q := tries(b, s.maxWidth)
+	if q.Len() == 0 {
+		// every cell is too wide to guess
+		s.cut = true
+		return false
+	}
+
+	cut := s.cut
+
+	// look for the lowest bitcount candidate
+	for q.Len() > 0 {
+		c := heap.Pop(&q).(cqueue.PrioCoord).Coord
+		i := b.At(c).Possibilities()
+		s.cut = false
+
+		vs := [9]cell.ValT{}
+		n := 0
+		for ; i.Next(); n++ {
+			vs[n] = i.Value()
+		}
+		if s.params.Descending {
+			slices.Reverse(vs[:n])
+		}
+		// with naked singles disabled a cell can have a single candidate left, filling it is forced, not a guess
+		// deepening the search
+		next := depth + 1
+		if n == 1 {
+			next = depth
+		}
+
+		// for all candidates for the cell
+		for _, v := range vs[:n] {
+			bb := *b
+			n := len(s.trace)
+
+			bb.Fill(c, v)
+			s.trace.add(Step{Technique: Guess, Coord: c, Value: v, Hash: bb.Hash()})
+			s.stats.Guesses[coord.Ctoi(c)]++
+			if s.solve(&bb, next) {
+				*b = bb
+				return true
+			}
+			s.trace = s.trace[:n]
+			s.stats.Backtracks[v-1]++
+		}
+
+		if !s.cut {
+			// none of the candidates of c work, no point guessing other cells
+			s.cut = cut
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package solve
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/puzzles"
+)
+
+// the layouts of the variant samples
+var sampleLayouts = map[string]coord.Layout{
+	"x":        coord.X,
+	"windoku":  coord.Windoku,
+	"disjoint": coord.DisjointGroups,
+}
+
+// the board of the 81 character line p in layout l
+func lineBoard(t testing.TB, l coord.Layout, p string) board.Board {
+	t.Helper()
+	if len(p) != 9*9 {
+		t.Fatalf("%q: %d characters", p, len(p))
+	}
+	v := [9 * 9]cell.ValT{}
+	for ix, ch := range p {
+		if '1' <= ch && ch <= '9' {
+			v[ix] = cell.ValT(ch - '0')
+		}
+	}
+	return board.FromValues(l, v)
+}
+
+// the boards of the embedded samples, standard and variant
+func sampleBoards(t testing.TB) []board.Board {
+	t.Helper()
+	bs := []board.Board{}
+	for _, d := range []puzzles.Difficulty{puzzles.Easy, puzzles.Medium, puzzles.Hard} {
+		for _, p := range puzzles.Samples(d) {
+			bs = append(bs, lineBoard(t, coord.Standard, p))
+		}
+	}
+	for v, l := range sampleLayouts {
+		for _, p := range puzzles.VariantSamples(v) {
+			bs = append(bs, lineBoard(t, l, p))
+		}
+	}
+	return bs
+}
+
+// the sample boards with boards of random cells of their solutions, and boards of random clues that mostly contradict
+func testBoards(t testing.TB) (consistent, random []board.Board) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	consistent = sampleBoards(t)
+	for _, b := range sampleBoards(t) {
+		r, err := DLX{Limit: 1}.Solve(context.Background(), &b)
+		if err != nil || r.Status != Solved {
+			t.Fatalf("%s: %v %v", b.Line(), r.Status, err)
+		}
+		for range 10 {
+			p := board.New(b.Layout())
+			for _, ix := range rng.Perm(9 * 9)[:20+rng.Intn(20)] {
+				p.Give(coord.Itoc(ix), r.Solution.Cell(ix).Value)
+			}
+			consistent = append(consistent, p)
+		}
+		for range 10 {
+			p := board.New(b.Layout())
+			for _, ix := range rng.Perm(9 * 9)[:10+rng.Intn(20)] {
+				p.Give(coord.Itoc(ix), cell.ValT(rng.Intn(9)+1))
+			}
+			random = append(random, p)
+		}
+	}
+	return consistent, random
+}
+
+// singlePossible as it was before the peers of the fills were queued, filling the singles while scanning the board
+func scanSinglePossible(b *board.Board, t *Trace) bool {
+	r := false
+	for ix := range 9 * 9 {
+		if c := b.Cell(ix); c.IsSingle() {
+			b.Fill(coord.Itoc(ix), c.FirstPossibility())
+			t.add(Step{Technique: NakedSingle, Coord: coord.Itoc(ix), Value: c.FirstPossibility(), Hash: b.Hash()})
+			r = true
+		}
+	}
+	return r
+}
+
+// fills the singles of b as singles does with the naked single pass single, returning the number of board scans
+func singlesScans(b *board.Board, single func(*board.Board, *Trace) bool) int {
+	n := 0
+	for {
+		n++
+		if single(b, nil) {
+			continue
+		}
+		n++
+		if !onlyPlace(b, nil) {
+			return n
+		}
+	}
+}
+
+// do a and b have the same values and candidates
+func sameCells(a, b board.Board) bool {
+	for ix := range 9 * 9 {
+		if a.Cell(ix) != b.Cell(ix) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSinglePossibleSameFixpoint(t *testing.T) {
+	consistent, random := testBoards(t)
+	old, new := 0, 0
+	for _, b := range consistent {
+		want, got := b, b
+		old += singlesScans(&want, scanSinglePossible)
+		new += singlesScans(&got, singlePossible)
+		if !sameCells(got, want) {
+			t.Errorf("%s: singles gave\n%s\nthe scanning pass\n%s", b.Line(), got.Line(), want.Line())
+		}
+	}
+	// the fills of a board with a contradiction depend on their order, only the contradiction has to be found by both
+	for _, b := range random {
+		want, got := b, b
+		singlesScans(&want, scanSinglePossible)
+		singlesScans(&got, singlePossible)
+		if want.Contradicts() != got.Contradicts() || !want.Contradicts() && !sameCells(got, want) {
+			t.Errorf("%s: singles gave\n%s\nthe scanning pass\n%s", b.Line(), got.Line(), want.Line())
+		}
+	}
+	if new >= old {
+		t.Errorf("%d board scans, the scanning pass took %d", new, old)
+	}
+	t.Logf("%d board scans, the scanning pass took %d", new, old)
+}
+
+func BenchmarkSingles(b *testing.B) {
+	bs := sampleBoards(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, p := range bs {
+			Singles(&p, nil)
+		}
+	}
+}
+
+func BenchmarkScanningSingles(b *testing.B) {
+	bs := sampleBoards(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, p := range bs {
+			for scanSinglePossible(&p, nil) || onlyPlace(&p, nil) {
+			}
+		}
+	}
+}
+
+// the cells of house h of b where v is a candidate, counted as onlyPlace did before the bitboards
+func countPlaces(b *board.Board, h, v int) (n, last int) {
+	for _, ix := range b.Layout().HouseIndices()[h] {
+		if b.Cell(ix).IsPossible(cell.ValT(v)) {
+			n, last = n+1, ix
+		}
+	}
+	return n, last
+}
+
+// onlyPlace as it was before the bitboards, filling the first hidden single found by counting the candidates of the
+// houses and returning
+func countingOnlyPlace(b *board.Board, t *Trace) bool {
+	for h := range b.Layout().HouseIndices() {
+		for v := 1; v <= 9; v++ {
+			if n, ix := countPlaces(b, h, v); n == 1 {
+				b.Fill(coord.Itoc(ix), cell.ValT(v))
+				t.add(Step{Technique: HiddenSingle, Coord: coord.Itoc(ix), Value: cell.ValT(v), Hash: b.Hash()})
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestOnlyPlaceFindsCountedSingles(t *testing.T) {
+	consistent, _ := testBoards(t)
+	for _, b := range consistent {
+		want, got := b, b
+		for countingOnlyPlace(&want, nil) {
+		}
+		tr := Trace{}
+		for onlyPlace(&got, &tr) {
+		}
+		if !sameCells(got, want) {
+			t.Errorf("%s: hidden singles gave\n%s\ncounting gave\n%s", b.Line(), got.Line(), want.Line())
+		}
+
+		// every step is the only place of its digit in its house by counting, on the board before the step
+		p := b
+		for _, st := range tr {
+			if n, ix := countPlaces(&p, st.House.ID, int(st.Value)); n != 1 || ix != coord.Ctoi(st.Coord) {
+				t.Errorf("%s: %v is not a hidden single by counting", b.Line(), st)
+				break
+			}
+			p.Fill(st.Coord, st.Value)
+		}
+	}
+}
+
+func BenchmarkOnlyPlace(b *testing.B) {
+	bs := sampleBoards(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, p := range bs {
+			for onlyPlace(&p, nil) {
+			}
+		}
+	}
+}
+
+func BenchmarkCountingOnlyPlace(b *testing.B) {
+	bs := sampleBoards(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, p := range bs {
+			for countingOnlyPlace(&p, nil) {
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Solvers for sudoku boards of any layout: the logic solver with its trace of steps, dancing links, and the compact
+// solver for constrained targets
+//
+// Example:
+//
+// b, err := formats.ParseLine(coord.Standard, puzzle)
+//
+//	if err != nil {
+//	  return err
+//	}
+//
+// s, r, err := solve.Solve(ctx, solve.Auto(solve.Need{Count: true}), b)
+//
+//	if err != nil {
+//	  return err
+//	}
+//
+//	if err := r.Err(); err != nil {
+//	  return err // no solution, or more than one
+//	}
+//
+// fmt.Printf("%+v", &s)
+package solve
+
+import (
+	"context"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+)
+
+// a solving backend
+//
+// solvers never modify the board they are given, whatever the status or error of the solve: the search works on
+// copies, and the solution, or the board a Stuck search left, is a separate board in the result. The pointer only saves
+// a copy, the Solve function takes the board by value for callers that don't want to rely on the backend.
+type Solver interface {
+	Solve(ctx context.Context, b *board.Board) (Result, error)
+}
+
+// solves b with s, returning the board of the result next to it
+//
+// b is a copy, so it stays as it was even with a backend that doesn't keep the promise of Solver. The board is the
+// zero Board unless the status is Solved, Multiple or Stuck.
+func Solve(ctx context.Context, s Solver, b board.Board) (board.Board, Result, error) {
+	r, err := s.Solve(ctx, &b)
+	return r.Solution, r, err
+}
+
+// what the caller wants from a solve, used for picking a backend
+type Need struct {
+	Explain bool // the solving path matters, not only the solution
+	Count   bool // tell unique puzzles apart from ones with multiple solutions
+}
+
+// singles and iterative deepening guesses
+//
+// with guessing disabled the solver stops with Stuck once the enabled singles run out
+type Logic struct {
+	Disabled TechniqueSet // techniques not to use
+}
+
+func (s Logic) Solve(ctx context.Context, b *board.Board) (Result, error) {
+	r := iterate(ctx, b, Profile, s.Disabled)
+	if r.Status == Aborted {
+		return r, r.Cause
+	}
+	return r, nil
+}
+
+// the logic solver for puzzles that dancing links found to have a unique solution
+type UniqueLogic struct {
+	Disabled TechniqueSet // techniques not to use
+}
+
+func (s UniqueLogic) Solve(ctx context.Context, b *board.Board) (Result, error) {
+	r, err := DLX{Limit: 2}.Solve(ctx, b)
+	if err != nil || r.Status != Solved {
+		return r, err
+	}
+	return Logic(s).Solve(ctx, b)
+}
+
+// dancing links, counting solutions up to limit
+//
+// the exact cover matrix only has the houses of the layout, a board of a layout with other rules is searched by Compact
+// instead.
+type DLX struct{ Limit int }
+
+func (s DLX) Solve(ctx context.Context, b *board.Board) (Result, error) {
+	if !b.Layout().HousesOnly() {
+		return Compact(s).Solve(ctx, b)
+	}
+	start := time.Now()
+	x := newDLX(b)
+
+	n, err := x.search(ctx, s.Limit)
+	r := Result{Stats: Stats{Nodes: x.steps, Duration: time.Since(start)}}
+	switch {
+	case err != nil:
+		return aborted(ctx, r)
+	case n == 0:
+		r.Status = Unsolvable
+		return r, nil
+	case n > 1:
+		r.Status = Multiple
+	}
+
+	r.Solution = *b
+	for _, c := range x.first {
+		if r.Solution.Cell(c / 9).IsEmpty() {
+			r.Solution.Fill(coord.Itoc(c/9), cell.ValT(c%9+1))
+		}
+	}
+	return r, nil
+}
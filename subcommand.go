@@ -0,0 +1,38 @@
+package main
+
+import (
+	"maps"
+	"slices"
+)
+
+// the mode flags the subcommands stand for, solve is the default mode without a flag
+var subcommands = map[string]string{
+	"solve":    "",
+	"generate": "-generate",
+	"rate":     "-rate",
+	"check":    "-verify",
+	"audit":    "-audit",
+	"batch":    "-batch",
+	"repl":     "-repl",
+	"play":     "-play",
+	"serve":    "-serve",
+}
+
+// names of the subcommands, sorted
+func subcommandNames() []string { return slices.Sorted(maps.Keys(subcommands)) }
+
+// the command line args with a leading subcommand replaced by its mode flag, so that sudoku rate a.sdm is sudoku -rate
+// a.sdm
+func expandSubcommand(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	f, ok := subcommands[args[0]]
+	switch {
+	case !ok:
+		return args
+	case f == "":
+		return args[1:]
+	}
+	return append([]string{f}, args[1:]...)
+}
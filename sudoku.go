@@ -1,241 +1,782 @@
 package main
 
 import (
-	"container/heap"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-
-	"github.com/phaul/sudoku/cell"
+	"io"
+	"maps"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cache"
 	"github.com/phaul/sudoku/coord"
-	"github.com/phaul/sudoku/cqueue"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/gen"
+	"github.com/phaul/sudoku/rate"
+	"github.com/phaul/sudoku/solve"
 )
 
-type board [9 * 9]cell.Cell // a sudoku board
+// layouts by variant name
+var variants = map[string]coord.Layout{
+	"standard":    coord.Standard,
+	"x":           coord.X,
+	"windoku":     coord.Windoku,
+	"disjoint":    coord.DisjointGroups,
+	"latin":       coord.Latin,
+	"anti-knight": coord.AntiKnight,
+	"killer":      coord.Standard, // the cages come with the puzzle
+}
 
-// address a board with x, y 0-8 coordinates. 0, 0 is the top left corner and 8, 0 is the top right
-func (b *board) at(c coord.Coord) *cell.Cell {
-	return &b[coord.Ctoi(c)]
+// formats of the -steps lines by notation name
+var notations = map[string]func(solve.Step) string{
+	"sudogo": solve.Step.String,
+	"hodoku": formats.HodokuNotation,
 }
 
-// sets all cells to all 9 digits are possible
-func (b *board) allPossible() {
-	i := coord.All()
+// exit codes of solving
+const (
+	exitSolved     = 0 // the puzzle has a unique solution
+	exitUnsolvable = 1 // the puzzle has no solution
+	exitMultiple   = 2 // the puzzle has more than one solution
+	exitTimeout    = 3 // -timeout expired before the puzzle was solved
+	exitParse      = 4 // the puzzle couldn't be parsed
+	exitUsage      = 5 // invalid command line or other error
+	exitMemory     = 6 // the heap grew over -max-memory before the puzzle was solved
+	exitStuck      = 7 // the enabled techniques ran out before the puzzle was solved
+)
 
-	for i.Next() {
-		b.at(i.Value().(coord.Coord)).SetAll()
-	}
+func usage() {
+	o := flag.CommandLine.Output()
+	fmt.Fprintf(o, "Usage: %s [command] [flags] [puzzle or files]\n\n", os.Args[0])
+	fmt.Fprintf(o, "Solves puzzle given in the 81 character line, hodoku library, printed grid, .ss, 729 character\n")
+	fmt.Fprintf(o, "sukaku or killer format. A puzzle of - is read from standard input, as is a missing puzzle unless\n")
+	fmt.Fprintf(o, "standard input is a terminal.\n\n")
+	fmt.Fprintf(o, "Modes reading sdm or puzzle bank files read OpenSudoku xml collections too, except -transform, and\n")
+	fmt.Fprintf(o, "read standard input for a file named -.\n\n")
+	fmt.Fprintf(o, "The command is one of %s.\n", strings.Join(subcommandNames(), ", "))
+	fmt.Fprintf(o, "It is the same as the flag of its mode, like rate for -rate or check for -verify, solve is\n")
+	fmt.Fprintf(o, "the default.\n\nFlags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(o, `
+Exit codes when solving:
+  %d  solved, the solution is unique
+  %d  unsolvable
+  %d  multiple solutions
+  %d  timeout
+  %d  the puzzle couldn't be parsed
+  %d  invalid command line or other error
+  %d  memory limit exceeded
+  %d  stuck, the puzzle needs a disabled technique
+`, exitSolved, exitUnsolvable, exitMultiple, exitTimeout, exitParse, exitUsage, exitMemory, exitStuck)
 }
 
-// fill a cell in the board at c with v
-func (b *board) fill(c coord.Coord, v cell.ValT) {
-	*b.at(c) = cell.New(v)
+func main() {
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	flag.Usage = usage
+	generate := flag.Bool("generate", false, "generate a puzzle instead of solving one")
+	variant := flag.String("variant", "standard", "variant to generate or solve: standard, x, windoku, disjoint, latin, "+
+		"anti-knight or killer; a killer puzzle is solved as killer whatever the variant")
+	sizes := cageSizes(gen.CageSizes)
+	flag.Var(&sizes, "cage-sizes", "comma separated weights of the sizes of the cages of a killer -generate, starting "+
+		"with the weight of the 1 cell cages")
+	bankFile := flag.String("bank", "", "file of canonical solution grids that -generate samples and digs instead of "+
+		"filling a random grid, standard variant only")
+	grids := flag.Int("grids", 0, "build a -bank of this many solution grids instead of solving")
+	level := flag.String("difficulty", "", "only print a -generate puzzle of this difficulty: "+
+		strings.Join(difficultyNames(), ", "))
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed for generation, the same seed generates the same "+
+		"puzzles and -grids whatever the -workers")
+	backend := flag.String("solver", "auto", "solving backend: auto, logic, dlx or compact")
+	steps := flag.Bool("steps", false, "print the solving steps")
+	quiet := flag.Bool("quiet", false, "don't print anything when solving, only set the exit code")
+	timeout := flag.Duration("timeout", 0, "give up solving after this long, 0 for no limit, or a limit of "+
+		requestTimeout.String()+" on a request of -serve")
+	var maxMemory byteSize
+	flag.Var(&maxMemory, "max-memory", "abort once the heap grows over this many bytes, with an optional K, M or G "+
+		"suffix, 0 for no limit")
+	trust := flag.Bool("trust-marks", false, "keep the pencil marks of a hodoku puzzle, only dropping the candidates "+
+		"its values rule out, instead of recomputing all candidates from the values")
+	redundant := flag.Bool("redundant", false, "list the clues of the puzzle that can be taken out one at a time with the "+
+		"solution staying unique, instead of solving it")
+	showStats := flag.Bool("stats", false, "print the search statistics and the peak heap after solving")
+	batch := flag.Bool("rate", false, "rate the puzzles of the sdm files given as arguments")
+	report := flag.String("report", "", "write a per puzzle rating report to this .csv or .json file")
+	check := flag.Bool("verify", false, "verify the puzzles of the sdm files given as arguments, exiting with 1 if any is "+
+		"invalid, has too few clues or doesn't have a unique solution, and with 2 on errors")
+	audits := flag.Bool("audit", false, "count the solutions of the puzzles of the sdm files given as arguments on "+
+		"-workers, listing the ones without a unique solution and exiting with 1 if there are any, and with 2 on errors")
+	progress := flag.String("progress", "", "file to save the progress of an -audit in, an -audit with the same file "+
+		"resumes after the puzzles done")
+	many := flag.Bool("batch", false, "solve the puzzles of the sdm files given as arguments in parallel, printing a "+
+		"solution line per puzzle")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of parallel workers for -batch, -audit, -grids and a "+
+		"-generate with -difficulty")
+	file := flag.String("f", "", "read the puzzle to solve from this file, - for standard input; the modes reading sdm "+
+		"files read it after the files given as arguments")
+	output := flag.String("output", "text", "output of solving, -batch, -rate, -audit and -generate: text, jsonl for a "+
+		"json object per puzzle as soon as it's done, json for the solving steps as -json prints them, hodoku for the "+
+		"solving steps as hodoku library lines only, or pdf for a printable page of the puzzle and one of its solution")
+	flag.StringVar(output, "o", "text", "short for -output")
+	heat := flag.String("heatmap", "", "write where the logic solver guessed in a -batch, and which digits it took back, "+
+		"to this .csv or .png file")
+	pack := flag.String("pack", "", "build a progression pack of the puzzles of the sdm files given as arguments, or the "+
+		"built in samples, writing it to <pack>.sdm, to <pack>.xml in the OpenSudoku format and to the printable <pack>.pdf with an answer key. "+
+		"The ratings are kept in <pack>.ratings and reused by later builds")
+	ramp := flag.String("progression", "10,10,10", "number of easy, medium, hard and optionally diabolical "+
+		"puzzles in a -pack")
+	symmetry := flag.String("symmetry", "", "only put puzzles with this symmetry of the clue pattern in a -pack: "+
+		strings.Join(symmetryNames(), ", "))
+	playing := flag.Bool("play", false, "play the puzzle given as argument, or a -generate puzzle of -difficulty, on the "+
+		"terminal: arrows move, digits place, p switches to pencil marks, ? gives hints, u undoes and q quits")
+	interactive := flag.Bool("repl", false, "read play commands like set r4c7 3, hint and undo from standard input, on the "+
+		"puzzle given as argument or loaded with the load command; help lists the commands")
+	serving := flag.Bool("serve", false, "serve POST /solve, /rate, /hint and /generate, and GET /healthz and /readyz, "+
+		"over http on -addr, taking puzzles in any format or boards in json and answering in json")
+	addr := flag.String("addr", "localhost:8080", "address -serve listens on")
+	answers := flag.Int("cache", 1024, "number of /solve and /rate answers -serve remembers, 0 to solve every request")
+	answerTTL := flag.Duration("cache-ttl", time.Hour, "time -serve remembers an answer for, 0 until it's evicted")
+	cert := flag.String("tls-cert", "", "certificate file of -serve, serving https with -tls-key")
+	key := flag.String("tls-key", "", "private key file of the -tls-cert certificate")
+	keys := flag.String("api-keys", "", "file of the api keys of -serve, one per line; the POST endpoints then need one "+
+		"as a bearer token or in an X-API-Key header")
+	osk := flag.String("opensudoku", "", "write the puzzles of the sdm files given as arguments to this OpenSudoku xml "+
+		"collection")
+	transform := flag.Bool("transform", false, "write the puzzles of the sdm or puzzle bank files given as arguments to "+
+		"standard output turned by -rotate, mirrored by -mirror and relabeled by -relabel, keeping everything else of the files")
+	rotate := flag.Int("rotate", 0, "degrees to turn the puzzles of a -transform clockwise, a multiple of 90")
+	mirror := flag.String("mirror", "", "mirror the puzzles of a -transform: "+strings.Join(mirrorNames(), ", "))
+	relabel := flag.String("relabel", "", "relabel the digits of a -transform: a permutation of 123456789, the digit "+
+		"replacing 1 first, or seed=n for a random permutation")
+	tuning := flag.Bool("tune", false, "sweep the logic solver search parameters over the puzzles of the sdm files given "+
+		"as arguments, printing the fastest configurations and writing the best to -profile")
+	prof := flag.String("profile", "", "json file of logic solver search parameters, loaded when solving and written by "+
+		"-tune")
+	pencil := flag.Bool("candidates", false, "print the puzzle, and the board the techniques left when stuck, with the "+
+		"candidates of every empty cell")
+	md := flag.Bool("markdown", false, "print boards and steps as markdown tables")
+	ss := flag.Bool("ss", false, "print the -generate puzzle and its solution, or the solution of a puzzle, in the simple "+
+		"sudoku .ss format")
+	themeName := flag.String("theme", "", "glyphs and colors of the printed boards: "+
+		strings.Join(slices.Sorted(maps.Keys(board.Themes)), ", ")+"; dark with -color, plain without")
+	color := flag.String("color", "auto", "color the givens, the placed digits and the last placement of the printed "+
+		"boards: auto on a terminal unless NO_COLOR is set, always or never")
+	notation := flag.String("notation", "sudogo", "notation of the -steps lines: "+
+		strings.Join(slices.Sorted(maps.Keys(notations)), ", "))
+	hodoku := flag.Bool("hodoku", false, "print the solving steps as hodoku library lines")
+	asJSON := flag.Bool("json", false, "print the solving steps as a json document, described by schema/trace-v1.json, "+
+		"the same as -output json")
+	shell := flag.String("completion", "", "print the completion script for bash, zsh or fish")
+	var enable, disable techniqueList
+	flag.Var(&enable, "enable", "solve and -rate with only these techniques, repeatable or comma separated: "+
+		strings.Join(techniqueNames(), ", "))
+	flag.Var(&disable, "disable", "solve and -rate without these techniques, repeatable or comma separated")
+	var frame animation
+	flag.Var(&frame, "animate", "replay the solving steps in place, optionally with the delay between them in ms")
+	if err := flag.CommandLine.Parse(expandSubcommand(os.Args[1:])); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(exitUsage)
+	}
 
-	i := coord.Composed(coord.Composed(coord.Row(c), coord.Column(c)), coord.Box(c))
+	if *shell != "" {
+		if err := completion(os.Stdout, *shell, filepath.Base(os.Args[0])); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
+		}
+		return
+	}
 
-	for i.Next() {
-		c = i.Value().(coord.Coord)
-		b.at(c).Drop(v)
+	args := flag.Args()
+	if *file != "" {
+		args = append(args, *file)
 	}
-}
 
-// look for a cell that has a single possibility and fill
-//
-// return true if any were found or false otherwise
-func (b *board) singlePossible() bool {
-	r := false
-	i := coord.All()
+	if *asJSON {
+		*output = "json"
+	}
+	if !slices.Contains(outputs, *output) {
+		fmt.Fprintf(os.Stderr, "unknown output %q, expected one of %s\n", *output, strings.Join(outputs, ", "))
+		os.Exit(exitUsage)
+	}
+	// the modes writing a line per puzzle only have the text and jsonl outputs
+	if (*audits || *batch || *many) && *output != "text" && *output != "jsonl" {
+		fmt.Fprintf(os.Stderr, "-output %s is only for solving\n", *output)
+		os.Exit(exitUsage)
+	}
+	out := newJSONLines(*output, os.Stdout)
+	// the text that isn't a result goes next to the results to stdout, or out of their way to stderr
+	text := io.Writer(os.Stdout)
+	if out != nil {
+		text = os.Stderr
+	}
 
-	for i.Next() {
-		co := i.Value().(coord.Coord)
-		c := b.at(co)
+	ctx, guard := watchMemory(context.Background(), uint64(maxMemory))
 
-		if c.IsSingle() {
-			b.fill(co, c.FirstPossibility())
-			r = true
+	if *tuning {
+		if err := tune(ctx, os.Stdout, args, *prof); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
 	}
-	return r
-}
 
-// find a digit that can only go in one place, and fill it in
-//
-// returns true if one found
-func (b *board) onlyPlace() bool {
-	i := coord.Composed(coord.Composed(coord.AllRows(), coord.AllColumns()), coord.AllBoxes())
-
-	for i.Next() {
-		r := i.Value().(coord.Iterator)
-		counts := [9]int{}
-
-		for r.Next() {
-			c := b.at(r.Value().(coord.Coord))
-			for j := 1; j <= 9; j++ {
-				if c.IsPossible(cell.ValT(j)) {
-					counts[j-1] += 1
-				}
-			}
+	if *prof != "" {
+		p, err := loadProfile(*prof)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
 		}
-		r.Reset()
-		for r.Next() {
-			co := r.Value().(coord.Coord)
-			for j := 1; j <= 9; j++ {
-				if b.at(co).IsPossible(cell.ValT(j)) && counts[j-1] == 1 {
-					b.fill(co, cell.ValT(j))
-					return true
-				}
-			}
+		solve.Profile = p
+	}
+
+	if *check {
+		n, err := verify(ctx, os.Stdout, args)
+		switch {
+		case err != nil:
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		case n > 0:
+			os.Exit(1)
 		}
+		return
 	}
 
-	return false
-}
+	if *osk != "" {
+		if err := openSudokuMain(*osk, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-// wrapper for solving with iterative deepening
-// tune constants here for performance
-// maxDepth limits the number of guesses allowed before solve returns with false
-// maxWidth limits where guesses can happen, don't guess a cell if it has more possiblities than maxWidth
-func (b *board) iterate() {
-	for maxDepth := 3; true; maxDepth++ {
-		if b.solve(0, maxDepth, max(maxDepth/3, 2)) {
-			return
+	if *transform {
+		t, err := parseTransform(*rotate, *mirror, *relabel)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
+		}
+		if err := transformMain(os.Stdout, t, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
 	}
-}
 
-// tries to do a solve
-// first it fills in what we know for sure
-// then checks if solved or has a contradiction due to incorrect guess
-// then tries the easiest guess
-func (b *board) solve(depth, maxDepth, maxWidth int) bool {
-	// fmt.Printf("%d / %d\n", depth, maxDepth)
-	if depth >= maxDepth {
-		return false
+	if *audits {
+		n, err := audit(ctx, os.Stdout, os.Stderr, out, args, *workers, *progress)
+		switch {
+		case err != nil:
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		case n > 0:
+			os.Exit(1)
+		}
+		return
 	}
-	for b.singlePossible() || b.onlyPlace() {
+
+	if *batch {
+		if err := rateBatch(ctx, text, out, args, *report, disabledTechniques(enable, disable)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
-	if b.solved() {
-		return true
+
+	if *pack != "" {
+		p, err := parseProgression(*ramp)
+		var keep func(*board.Board) bool
+		if err == nil {
+			keep, err = symmetryFilter(*symmetry)
+		}
+		if err == nil {
+			err = packMain(ctx, os.Stderr, *pack, p, keep, args)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
-	if b.contradicts() {
-		return false
+
+	if *many {
+		s := solve.Auto(solve.Need{Count: true, Explain: *heat != ""})
+		if *backend != "auto" {
+			var ok bool
+			if s, ok = solve.Solvers[*backend]; !ok {
+				fmt.Fprintf(os.Stderr, "unknown solver %q\n", *backend)
+				os.Exit(exitUsage)
+			}
+		}
+		if _, ok := s.(solve.DLX); ok && *heat != "" {
+			fmt.Fprintln(os.Stderr, "-heatmap needs the logic solver")
+			os.Exit(exitUsage)
+		}
+		if err := solveBatch(ctx, os.Stdout, os.Stderr, out, args, s, *workers, *heat); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
+		}
+		fmt.Fprintf(os.Stderr, "peak heap %s\n", formatBytes(guard.stop()))
+		return
 	}
-	return b.try(depth, maxDepth, maxWidth)
-}
 
-func (b *board) solved() bool {
-	i := coord.All()
+	l, ok := variants[*variant]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown variant %q\n", *variant)
+		os.Exit(exitUsage)
+	}
+	theme, err := pickTheme(*themeName, *color, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
+	}
+	notate, ok := notations[*notation]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown notation %q\n", *notation)
+		os.Exit(exitUsage)
+	}
+	puzzle, err := puzzleArg(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
+	}
 
-	for i.Next() {
-		if b.at(i.Value().(coord.Coord)).IsEmpty() {
-			return false
+	if *interactive {
+		prompt := ""
+		if isTerminal(os.Stdin) {
+			prompt = "> "
 		}
+		if err := repl(ctx, os.Stdin, os.Stdout, l, theme, puzzle, prompt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
+		}
+		return
 	}
-	return true
-}
 
+	if *serving {
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		sv := &server{
+			layout: l, variant: *variant, sizes: sizes, bank: *bankFile, workers: *workers, timeout: *timeout,
+			disable: disabledTechniques(enable, disable), cert: *cert, key: *key,
+		}
+		if *answers > 0 {
+			sv.answers = cache.New[answerKey, any](*answers, *answerTTL)
+		}
+		if *keys != "" {
+			ks, err := readAPIKeys(*keys)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitUsage)
+			}
+			sv.auth = ks
+		}
+		if err := serveMain(ctx, os.Stderr, *addr, sv); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
+		}
+		return
+	}
 
-// coordinates to try in the order of least amount of possible candidates to most
-func (b *board) tries(maxWidth int) cqueue.Queue {
-  q := cqueue.New()
-	i := coord.All()
+	if *grids > 0 {
+		if err := bankMain(ctx, *bankFile, *grids, *workers, *seed); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
+		}
+		return
+	}
 
-	for i.Next() {
-		c := i.Value().(coord.Coord)
-		cell := b.at(c)
-		p := cell.PossibilityCount()
-		if 0 < p && p <= maxWidth {
-			heap.Push(&q, cqueue.PrioCoord{Count: p, Coord: c})
+	newPuzzle := func() (p, s board.Board, rt *rate.Rating, err error) {
+		next, err := generator(ctx, *bankFile, *variant, l, sizes)
+		switch {
+		case err != nil:
+		case *level == "":
+			p, s, err = next(rand.New(rand.NewSource(*seed)), &gen.Scratch{})
+		default:
+			var d rate.Difficulty
+			if d, err = rate.ParseDifficulty(*level); err != nil {
+				err = fmt.Errorf("%w, expected one of %s", err, strings.Join(difficultyNames(), ", "))
+				break
+			}
+			rt = new(rate.Rating)
+			p, s, *rt, err = gen.GenerateRatedParallel(ctx, d, generateTries, *workers, *seed, next)
 		}
+		return p, s, rt, err
 	}
 
-	return q
-}
+	if *playing {
+		var b board.Board
+		if puzzle == "" {
+			b, _, _, err = newPuzzle()
+		} else {
+			b, err = parsePuzzle(l, puzzle)
+		}
+		if err == nil {
+			err = playMain(ctx, os.Stdin, os.Stdout, b, theme)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
+		}
+		return
+	}
 
-func (b *board) try(depth, maxDepth, maxWidth int) bool {
-	// look for the lowest bitcount candidate
-	for q := b.tries(maxWidth); q.Len() > 0; {
-		c := heap.Pop(&q).(cqueue.PrioCoord).Coord
-		i := b.at(c).Possibilities()
-
-		// for all candidates for the cell
-		for i.Next() {
-			v := i.Value()
-			bb := board{}
-			copy(bb[:], b[:])
-
-			bb.fill(c, v)
-			if bb.solve(depth+1, maxDepth, maxWidth) {
-				copy(b[:], bb[:])
-				return true
+	if *generate {
+		if *output == "json" || *output == "hodoku" {
+			fmt.Fprintf(os.Stderr, "-output %s is only for solving\n", *output)
+			os.Exit(exitUsage)
+		}
+		p, s, rt, err := newPuzzle()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitUsage)
+		}
+		if *output == "pdf" {
+			if err := formats.WritePDFWithKey(os.Stdout, []string{"Puzzle"}, []board.Board{p}, []board.Board{s}, 1); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitUsage)
 			}
+			return
 		}
+		cages := formats.CageLines(&p)
+		if out != nil {
+			out.write(jsonResult{
+				Puzzle: p.Line(), Cages: cages, Status: solve.Solved.String(), Solution: s.Line(), Rating: rt,
+			})
+			if err := out.error(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitUsage)
+			}
+			return
+		}
+		// the cages of a killer follow the puzzle, a line each as the killer format has them
+		if *ss {
+			formats.WriteSimpleSudoku(os.Stdout, &p)
+			printLines(cages)
+			fmt.Println()
+			formats.WriteSimpleSudoku(os.Stdout, &s)
+			return
+		}
+		if *md {
+			p.Render(os.Stdout, board.Style{Markdown: true})
+			printLines(cages)
+			fmt.Println()
+			s.Render(os.Stdout, board.Style{Markdown: true})
+			return
+		}
+		if cages != nil {
+			fmt.Println(formats.KillerText(&p))
+		} else {
+			p.Render(os.Stdout, board.Style{Theme: theme})
+		}
+		fmt.Println("=========================")
+		s.Render(os.Stdout, board.Style{Theme: theme})
+		return
+	}
+
+	if puzzle == "" {
+		if isTerminal(os.Stdin) {
+			usage()
+			os.Exit(exitUsage)
+		}
+		puzzle = "-"
 	}
-	return false
+	os.Exit(solveMain(ctx, puzzle, solveOptions{
+		layout:    l,
+		guard:     guard,
+		stats:     *showStats && !*quiet,
+		trust:     *trust,
+		backend:   *backend,
+		steps:     *steps && !*quiet,
+		notation:  notate,
+		frame:     frame,
+		quiet:     *quiet,
+		timeout:   *timeout,
+		md:        *md,
+		pencil:    *pencil,
+		ss:        *ss,
+		hodoku:    *hodoku && !*quiet,
+		output:    *output,
+		disable:   disabledTechniques(enable, disable),
+		redundant: *redundant,
+		theme:     theme,
+	}))
 }
 
-// there is a cell that has no possible value left but also not filled in
-func (b *board) contradicts() bool {
-	i := coord.All()
+// command line options of solving
+type solveOptions struct {
+	backend   string                  // solver name or auto
+	steps     bool                    // print the trace
+	notation  func(solve.Step) string // formats the lines of the trace
+	frame     animation               // animation delay, 0 for printing the solution only
+	quiet     bool                    // don't print anything
+	timeout   time.Duration           // 0 for no timeout
+	md        bool                    // print markdown tables
+	pencil    bool                    // print the candidates of the empty cells of the boards
+	ss        bool                    // print the solution in the simple sudoku format only
+	hodoku    bool                    // print the trace as hodoku library lines
+	output    string                  // text, jsonl, json for the trace as a json document, hodoku or pdf
+	stats     bool                    // print the search statistics to stderr
+	trust     bool                    // keep the pencil marks of the puzzle instead of recomputing the candidates
+	layout    coord.Layout            // houses of the puzzle
+	guard     *memoryGuard            // watches the heap of the solve
+	disable   solve.TechniqueSet      // techniques the solver doesn't use
+	redundant bool                    // list the redundant clues instead of solving
+	theme     *board.Theme            // glyphs and colors of the text grid
+}
 
-	for i.Next() {
-		c := b.at(i.Value().(coord.Coord))
+// the puzzle to solve or play: the first argument, or the contents of the -f file fn
+func puzzleArg(fn string) (string, error) {
+	switch fn {
+	case "":
+		return flag.Arg(0), nil
+	case "-":
+		return fn, nil
+	}
+	in, err := os.ReadFile(fn)
+	if err != nil {
+		return "", err
+	}
+	p := strings.TrimSpace(string(in))
+	if p == "" {
+		return "", fmt.Errorf("%s: no puzzle in the file", fn)
+	}
+	return p, nil
+}
 
-		if c.Value == 0 && c.PossibilityCount() == 0 {
-			return true
-		}
+// parses the puzzle p in layout l, in the line, hodoku library, text grid, simple sudoku or sukaku format, or a killer
+// puzzle in the layout of its cages
+func parsePuzzle(l coord.Layout, p string) (board.Board, error) {
+	switch {
+	case strings.HasPrefix(p, ":"):
+		b, _, err := formats.ParseHodoku(l, p)
+		return b, err
+	case formats.IsSukaku(p):
+		return formats.ParseSukaku(l, p)
+	case formats.IsKiller(p):
+		return formats.ParseKiller(p)
+	case strings.Contains(p, "\n") || strings.Contains(p, "|"):
+		// the .ss format is a dialect of the text grid
+		return formats.ParseSimpleSudoku(l, p)
+	default:
+		return formats.ParseLine(l, p)
 	}
-	return false
 }
 
-func (b board) print() {
-	i := coord.All()
+// solves the puzzle p in any format parsePuzzle reads, returning the exit code. p of "-" is read from the standard
+// input.
+//
+// the text output prints the boards and the steps as the options ask, the others print only what they're named for
+func solveMain(ctx context.Context, p string, o solveOptions) int {
+	text := o.output == "text"
+	if o.quiet {
+		text, o.output = true, "text"
+	}
+	if o.quiet || o.md || !text || o.ss {
+		o.frame = 0
+	}
+	if !text {
+		o.md, o.steps, o.hodoku, o.pencil = false, false, o.output == "hodoku", false
+	}
 
-	for i.Next() {
-		c := i.Value().(coord.Coord)
-		if c.Y%3 == 0 && c.X == 0 {
-			fmt.Println("+---+---+---")
+	var err error
+	if p == "-" {
+		var in []byte
+		in, err = io.ReadAll(os.Stdin)
+		p = strings.TrimSpace(string(in))
+	}
+	var b board.Board
+	if err == nil {
+		b, err = parsePuzzle(o.layout, p)
+	}
+	if err != nil {
+		if !o.quiet {
+			fmt.Fprintln(os.Stderr, err)
 		}
-		if c.X%3 == 0 {
-			fmt.Print("|")
+		return exitParse
+	}
+
+	m := board.RecomputeMarks
+	// the candidates of a sukaku are its clues
+	if o.trust || formats.IsSukaku(p) {
+		m = board.TrustMarks
+	}
+	if err := b.Warm(m); err != nil {
+		if !o.quiet {
+			fmt.Fprintln(os.Stderr, err)
 		}
-		if b.at(c).Value == 0 {
-			fmt.Print(" ")
-		} else {
-			fmt.Print(b.at(c).Value)
+		return exitUnsolvable
+	}
+
+	if o.redundant {
+		return redundantMain(ctx, &b, o)
+	}
+
+	s := solve.Auto(solve.Need{Explain: o.steps || o.hodoku || o.output == "json" || o.frame > 0, Count: true})
+	if o.backend != "auto" {
+		var ok bool
+		if s, ok = solve.Solvers[o.backend]; !ok {
+			fmt.Fprintf(os.Stderr, "unknown solver %q\n", o.backend)
+			return exitUsage
 		}
-		if c.X == 8 {
-			fmt.Println("|")
+	}
+	if o.disable != 0 {
+		// only the logic solver works by techniques
+		switch o.backend {
+		case "auto", "logic":
+			s = solve.UniqueLogic{Disabled: o.disable}
+		default:
+			fmt.Fprintf(os.Stderr, "solver %q doesn't use techniques\n", o.backend)
+			return exitUsage
+		}
+	}
+
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	switch {
+	case o.md:
+		b.Render(os.Stdout, board.Style{Markdown: true})
+		fmt.Println()
+	case o.frame == 0 && !o.quiet && text && !o.ss:
+		b.Render(os.Stdout, board.Style{Theme: o.theme, Candidates: o.pencil})
+		fmt.Println("=========================")
+	}
+	r, err := s.Solve(ctx, &b)
+	if err != nil && r.Status != solve.Aborted {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	peak := o.guard.stop()
+	if o.stats {
+		fmt.Fprintf(os.Stderr, "%v, peak heap %s\n", r.Stats, formatBytes(peak))
+	}
+	if o.hodoku {
+		ls, err := formats.HodokuLines(b, r.Trace)
+		for _, l := range ls {
+			fmt.Println(l)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+	if err := writeSolve(os.Stdout, o.output, b, r); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	switch {
+	case o.steps && o.md:
+		formats.MarkdownTrace(os.Stdout, r.Trace)
+		fmt.Println()
+	case o.steps:
+		for _, st := range r.Trace {
+			fmt.Println(o.notation(st))
+		}
+	}
+	if !o.quiet && text {
+		switch {
+		case r.Status == solve.Stuck && o.pencil:
+			r.Solution.Render(os.Stdout, board.Style{Theme: o.theme, Candidates: true})
+			fmt.Println(r.Status)
+		case r.Status != solve.Solved:
+			fmt.Println(r.Status)
+		case o.md:
+			r.Solution.Render(os.Stdout, board.Style{Markdown: true})
+		case o.ss:
+			formats.WriteSimpleSudoku(os.Stdout, &r.Solution)
+		case o.frame > 0:
+			animate(b, r.Trace, time.Duration(o.frame), o.theme)
+		default:
+			s := board.Style{Theme: o.theme}
+			if len(r.Trace) > 0 {
+				s.Marks = []coord.Coord{r.Trace[len(r.Trace)-1].Coord}
+			}
+			r.Solution.Render(os.Stdout, s)
 		}
 	}
+
+	switch r.Status {
+	case solve.Unsolvable:
+		return exitUnsolvable
+	case solve.Multiple:
+		return exitMultiple
+	case solve.Stuck:
+		return exitStuck
+	case solve.Aborted:
+		if errors.Is(err, errMemory) {
+			if !o.quiet {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			return exitMemory
+		}
+		return exitTimeout
+	}
+	return exitSolved
 }
 
-func main() {
-	b := board{}
-	b.allPossible()
-  // https://sudoku2.com/play-the-hardest-sudoku-in-the-world/
-	b.fill(coord.Coord{X: 0, Y: 0}, 8)
-	b.fill(coord.Coord{X: 2, Y: 1}, 3)
-	b.fill(coord.Coord{X: 3, Y: 1}, 6)
-	b.fill(coord.Coord{X: 1, Y: 2}, 7)
-	b.fill(coord.Coord{X: 4, Y: 2}, 9)
-	b.fill(coord.Coord{X: 6, Y: 2}, 2)
-	b.fill(coord.Coord{X: 1, Y: 3}, 5)
-	b.fill(coord.Coord{X: 5, Y: 3}, 7)
-	b.fill(coord.Coord{X: 4, Y: 4}, 4)
-	b.fill(coord.Coord{X: 5, Y: 4}, 5)
-	b.fill(coord.Coord{X: 6, Y: 4}, 7)
-	b.fill(coord.Coord{X: 3, Y: 5}, 1)
-	b.fill(coord.Coord{X: 7, Y: 5}, 3)
-	b.fill(coord.Coord{X: 2, Y: 6}, 1)
-	b.fill(coord.Coord{X: 7, Y: 6}, 6)
-	b.fill(coord.Coord{X: 8, Y: 6}, 8)
-	b.fill(coord.Coord{X: 2, Y: 7}, 8)
-	b.fill(coord.Coord{X: 3, Y: 7}, 5)
-	b.fill(coord.Coord{X: 7, Y: 7}, 1)
-	b.fill(coord.Coord{X: 1, Y: 8}, 9)
-	b.fill(coord.Coord{X: 6, Y: 8}, 4)
-
-	b.print()
-	fmt.Println("=========================")
-	b.iterate()
-	b.print()
+// writes the outcome r of solving b to w in the output format o, the text output is left to solveMain
+func writeSolve(w io.Writer, o string, b board.Board, r solve.Result) error {
+	switch o {
+	case "json":
+		return formats.WriteTraceJSON(w, b, r)
+	case "jsonl":
+		jr := jsonResult{Puzzle: b.Line(), Cages: formats.CageLines(&b), Status: r.Status.String()}
+		if r.Status == solve.Solved {
+			jr.Solution = r.Solution.Line()
+		}
+		out := newJSONLines(o, w)
+		out.write(jr)
+		return out.error()
+	case "pdf":
+		key := []board.Board{}
+		if r.Status == solve.Solved {
+			key = append(key, r.Solution)
+		}
+		return formats.WritePDFWithKey(w, []string{"Puzzle"}, []board.Board{b}, key, 1)
+	}
+	return nil
+}
+
+// prints the redundant clues of b, a line per clue, returning the exit code
+func redundantMain(ctx context.Context, b *board.Board, o solveOptions) int {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	cs, err := solve.RedundantClues(ctx, b)
+	switch {
+	case errors.Is(err, solve.ErrUnsolvable):
+		return exitUnsolvable
+	case errors.Is(err, solve.ErrMultiple):
+		return exitMultiple
+	case errors.Is(err, errMemory):
+		return exitMemory
+	case errors.Is(err, solve.ErrTimeout):
+		return exitTimeout
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if !o.quiet {
+		for _, c := range cs {
+			fmt.Printf("r%dc%d=%d\n", c.Y+1, c.X+1, b.At(c).Value)
+		}
+	}
+	return exitSolved
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/phaul/sudoku/solve"
+)
+
+// technique names of a repeatable -enable or -disable flag
+type techniqueList []string
+
+func (l *techniqueList) String() string { return strings.Join(*l, ",") }
+
+func (l *techniqueList) Set(s string) error {
+	for _, n := range strings.Split(s, ",") {
+		if _, ok := solve.Techniques[n]; !ok {
+			return fmt.Errorf("unknown technique %q, expected one of %s", n, strings.Join(techniqueNames(), ", "))
+		}
+		*l = append(*l, n)
+	}
+	return nil
+}
+
+// names of the techniques, sorted
+func techniqueNames() []string { return slices.Sorted(maps.Keys(solve.Techniques)) }
+
+// the techniques left out by -enable and -disable: with any enabled only those are used, then the disabled ones are
+// dropped
+func disabledTechniques(enable, disable techniqueList) solve.TechniqueSet {
+	d := solve.TechniqueSet(0)
+	if len(enable) > 0 {
+		for _, t := range solve.Techniques {
+			d |= solve.TechniquesOf(t)
+		}
+		for _, n := range enable {
+			d &^= solve.TechniquesOf(solve.Techniques[n])
+		}
+	}
+	for _, n := range disable {
+		d |= solve.TechniquesOf(solve.Techniques[n])
+	}
+	return d
+}
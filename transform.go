@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/formats"
+)
+
+// names of the -mirror values
+func mirrorNames() []string {
+	return []string{
+		board.LeftRight.String(), board.TopBottom.String(), board.Diagonal.String(), board.AntiDiagonal.String(),
+	}
+}
+
+// the transformation of -rotate, -mirror and -relabel
+//
+// rotate is in degrees clockwise, a multiple of 90. relabel is either a permutation of the digits 1 to 9, the digit
+// replacing 1 first, or seed=n for a random permutation from the seed n.
+func parseTransform(rotate int, mirror, relabel string) (board.Transform, error) {
+	t := board.Transform{}
+	if rotate%90 != 0 {
+		return t, fmt.Errorf("rotation %d is not a multiple of 90 degrees", rotate)
+	}
+	t.Turns = rotate / 90
+
+	if mirror != "" {
+		s, err := board.ParseSymmetry(mirror)
+		if err != nil || s == board.Rotational || s == board.QuarterTurn {
+			return t, fmt.Errorf("unknown mirror %q, expected one of %s", mirror, strings.Join(mirrorNames(), ", "))
+		}
+		t.Mirror = s
+	}
+
+	if seed, ok := strings.CutPrefix(relabel, "seed="); ok {
+		n, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			return t, fmt.Errorf("invalid relabel seed %q", seed)
+		}
+		for i, d := range rand.New(rand.NewSource(n)).Perm(9) {
+			t.Relabel[i] = cell.ValT(d + 1)
+		}
+		return t, nil
+	}
+	if relabel == "" {
+		return t, nil
+	}
+	bad := fmt.Errorf("relabel %q is neither a permutation of 1 to 9 nor seed=n", relabel)
+	if len(relabel) != 9 {
+		return t, bad
+	}
+	seen := uint16(0)
+	for i, ch := range []byte(relabel) {
+		if ch < '1' || ch > '9' || seen&(1<<(ch-'1')) != 0 {
+			return t, bad
+		}
+		seen |= 1 << (ch - '1')
+		t.Relabel[i] = cell.ValT(ch - '0')
+	}
+	return t, nil
+}
+
+// writes the puzzles of the sdm or puzzle bank files fns transformed by t to w, in the format they were read in
+func transformMain(w io.Writer, t board.Transform, fns []string) error {
+	f := func(b *board.Board) board.Board { return b.Transform(t) }
+	for _, fn := range fns {
+		r := os.Stdin
+		if fn != "-" {
+			var err error
+			if r, err = os.Open(fn); err != nil {
+				return err
+			}
+		}
+		err := formats.Rewrite(w, r, f)
+		if fn != "-" {
+			r.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/cell"
+	"github.com/phaul/sudoku/coord"
+	"github.com/phaul/sudoku/play"
+)
+
+// keys of the -play terminal UI and what they do
+var tuiKeys = [][2]string{
+	{"arrows or hjkl", "move the cursor"},
+	{"1-9", "place the digit, or toggle the candidate in pencil mode"},
+	{"0, x or backspace", "erase the value"},
+	{"p", "switch between placing digits and pencil marks"},
+	{"c", "show or hide the candidates"},
+	{"a", "fill in the candidates of every empty cell"},
+	{"?", "hint, again for more of the same hint"},
+	{"u", "undo"},
+	{"q", "quit"},
+}
+
+// a play session on the terminal, a cursor on the board and the keys of tuiKeys
+type tui struct {
+	ctx        context.Context
+	session    *play.Session
+	theme      *board.Theme
+	cursor     coord.Coord
+	pencil     bool   // digits toggle candidates instead of placing values
+	candidates bool   // the board is drawn with the candidates of the empty cells
+	hints      int    // rungs of the hint ladder given for the position
+	status     string // outcome of the last key
+}
+
+// plays puzzle b with the keys read from the terminal in, which is put into unbuffered mode for the session, drawing
+// to out
+//
+// the moves are checked against the solution as they are made, mistakes are reported right away. The summary of the
+// session is printed on quitting.
+func playMain(ctx context.Context, in *os.File, out io.Writer, b board.Board, th *board.Theme) error {
+	if !isTerminal(in) {
+		return errors.New("-play needs a terminal")
+	}
+	s, err := play.New(ctx, b)
+	if err != nil {
+		return err
+	}
+	restore, err := rawTerminal(in)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	t := &tui{ctx: ctx, session: s, theme: th}
+	if t.theme.Highlight == "" {
+		// the cursor is the highlight
+		hl := *th
+		hl.Highlight = "7"
+		t.theme = &hl
+	}
+	s.Watch(t.watch)
+	if err := t.run(in, out); err != nil {
+		return err
+	}
+	sum := s.Summary()
+	fmt.Fprintf(out, "solved: %t, %d moves, %d mistakes, %d hints in %v\n",
+		sum.Solved, sum.Moves, sum.Mistakes, sum.Hints, sum.Duration.Round(time.Second))
+	return nil
+}
+
+// puts the terminal f in unbuffered mode without echo, returning the function that restores its settings
+//
+// signals are off too, so ^C comes in as a key, quitting the session with the terminal restored
+func rawTerminal(f *os.File) (func(), error) {
+	stty := func(args ...string) (string, error) {
+		c := exec.Command("stty", args...)
+		c.Stdin = f
+		out, err := c.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("saving the terminal settings: %w", err)
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, fmt.Errorf("setting up the terminal: %w", err)
+	}
+	return func() { stty(saved) }, nil
+}
+
+// draws the screen and handles the keys read from r until a quit or the end of r
+func (t *tui) run(r io.Reader, w io.Writer) error {
+	buf := make([]byte, 16)
+	for {
+		if err := t.draw(w); err != nil {
+			return err
+		}
+		n, err := r.Read(buf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, k := range keys(buf[:n]) {
+			if !t.key(k) {
+				return nil
+			}
+		}
+	}
+}
+
+// splits the bytes read from the terminal into keys, an escape sequence of an arrow is a key of its own
+func keys(b []byte) []string {
+	ks := []string{}
+	for len(b) > 0 {
+		if len(b) >= 3 && b[0] == 0x1b && b[1] == '[' {
+			ks = append(ks, string(b[:3]))
+			b = b[3:]
+			continue
+		}
+		ks = append(ks, string(b[:1]))
+		b = b[1:]
+	}
+	return ks
+}
+
+// handles the key k, returning false for quitting
+func (t *tui) key(k string) bool {
+	ix := coord.Ctoi(t.cursor)
+	x, y := ix%9, ix/9
+	var err error
+	switch k {
+	case "q", "\x03", "\x04":
+		return false
+	case "\x1b[A", "k":
+		y = (y + 8) % 9
+	case "\x1b[B", "j":
+		y = (y + 1) % 9
+	case "\x1b[C", "l":
+		x = (x + 1) % 9
+	case "\x1b[D", "h":
+		x = (x + 8) % 9
+	case "p":
+		t.pencil = !t.pencil
+		t.status = map[bool]string{false: "placing digits", true: "pencil marks"}[t.pencil]
+	case "c":
+		t.candidates = !t.candidates
+	case "a":
+		t.session.AutoFill()
+		t.status = "candidates filled in"
+	case "u":
+		t.status = "move taken back"
+		err = t.session.Undo()
+	case "0", "x", " ", "\x7f", "\b":
+		t.status = ""
+		err = t.session.Erase(t.cursor)
+	case "?":
+		// a hint doesn't change the position, asking again goes on with the same ladder
+		var ladder []string
+		if ladder, err = t.session.Hints(t.ctx, t.hints+1); err == nil {
+			t.hints = len(ladder)
+			t.status = ladder[len(ladder)-1]
+		}
+	default:
+		if len(k) == 1 && '1' <= k[0] && k[0] <= '9' {
+			err = t.digit(cell.ValT(k[0] - '0'))
+		}
+	}
+	t.cursor = coord.Itoc(y*9 + x)
+	if err != nil {
+		t.status = err.Error()
+	}
+	return true
+}
+
+// places v at the cursor, or toggles it as a candidate in pencil mode
+func (t *tui) digit(v cell.ValT) error {
+	t.status = ""
+	if t.pencil {
+		return t.session.Toggle(t.cursor, v)
+	}
+	if err := t.session.Place(t.cursor, v); err != nil {
+		return err
+	}
+	if sum := t.session.Summary(); sum.Solved {
+		t.status = fmt.Sprintf("solved in %v, q to quit", sum.Duration.Round(time.Second))
+	}
+	return nil
+}
+
+// follows the moves of the session, reporting mistakes and starting the hint ladder over once the position changes
+func (t *tui) watch(m play.Move) {
+	switch {
+	case m.Kind == play.MoveHint:
+		return
+	case m.Mistake:
+		t.status = fmt.Sprintf("%d at r%dc%d is not in the solution", m.Value, m.Row, m.Column)
+	}
+	t.hints = 0
+}
+
+// clears the terminal and draws the board with the cursor, the mode and the status line
+func (t *tui) draw(w io.Writer) error {
+	sb := strings.Builder{}
+	sb.WriteString("\x1b[H\x1b[2J")
+	b := t.session.Board()
+	s := board.Style{Theme: t.theme, Candidates: t.candidates, Marks: []coord.Coord{t.cursor}}
+	if err := b.Render(&sb, s); err != nil {
+		return err
+	}
+	mode := "pen"
+	if t.pencil {
+		mode = "pencil"
+	}
+	fmt.Fprintf(&sb, "\nr%dc%d  %s  %s\n\n", t.cursor.Y+1, t.cursor.X+1, mode, t.status)
+	for _, k := range tuiKeys {
+		fmt.Fprintf(&sb, "  %-18s %s\n", k[0], k[1])
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/solve"
+)
+
+// reads search parameters from the json profile fn, parameters missing from the file keep their default
+func loadProfile(fn string) (solve.Params, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return solve.Params{}, err
+	}
+	defer f.Close()
+
+	p := solve.DefaultParams
+	if err := json.NewDecoder(f).Decode(&p); err != nil {
+		return solve.Params{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	return p, p.Validate()
+}
+
+// the values swept by tune
+var sweep = struct {
+	depths, steps, widthDivs, minWidths []int
+}{
+	depths:    []int{1, 2, 3, 4, 6},
+	steps:     []int{1, 2, 3},
+	widthDivs: []int{2, 3, 4},
+	minWidths: []int{2, 3},
+}
+
+// the cost of solving a benchmark pack with some parameters
+type cost struct {
+	params solve.Params
+	total  time.Duration // all puzzles
+	worst  time.Duration // the slowest puzzle
+	nodes  int
+}
+
+// is c at least as good as o in both time measures, and better in one?
+func (c cost) dominates(o cost) bool {
+	return c.total <= o.total && c.worst <= o.worst && (c.total < o.total || c.worst < o.worst)
+}
+
+// times solving bs with p, taking the best of a few runs to smooth out noise
+func measure(ctx context.Context, bs []board.Board, p solve.Params) (cost, error) {
+	const runs = 3
+	c := cost{params: p, total: time.Duration(1<<63 - 1)}
+
+	for range runs {
+		r := cost{params: p}
+		for i := range bs {
+			res := solve.IterateWith(ctx, &bs[i], p)
+			if res.Status == solve.Aborted {
+				return c, context.Cause(ctx)
+			}
+			r.total += res.Stats.Duration
+			r.worst = max(r.worst, res.Stats.Duration)
+			r.nodes += res.Stats.Nodes
+		}
+		if r.total < c.total {
+			c = r
+		}
+	}
+	return c, nil
+}
+
+// sweeps the search parameters over the puzzles of files, printing the pareto front of total and worst case solving time
+// to w, fastest total first
+//
+// the configuration with the fastest total is written to the json profile out if it's not empty
+func tune(ctx context.Context, w io.Writer, files []string, out string) error {
+	bs := []board.Board{}
+	for _, fn := range files {
+		err := readPuzzles(fn, func(e formats.Entry, b board.Board, err error) error {
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", e.File, e.Line, err)
+			}
+			bs = append(bs, b)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(bs) == 0 {
+		return fmt.Errorf("no puzzles to tune on")
+	}
+
+	costs := []cost{}
+	for _, d := range sweep.depths {
+		for _, s := range sweep.steps {
+			for _, wd := range sweep.widthDivs {
+				for _, mw := range sweep.minWidths {
+					for _, desc := range []bool{false, true} {
+						p := solve.Params{Depth: d, Step: s, WidthDiv: wd, MinWidth: mw, Descending: desc}
+						c, err := measure(ctx, bs, p)
+						if err != nil {
+							return err
+						}
+						costs = append(costs, c)
+					}
+				}
+			}
+		}
+	}
+
+	front := []cost{}
+	for _, c := range costs {
+		if !slices.ContainsFunc(costs, func(o cost) bool { return o.dominates(c) }) {
+			front = append(front, c)
+		}
+	}
+	slices.SortFunc(front, func(a, b cost) int { return int(a.total - b.total) })
+
+	fmt.Fprintf(w, "%d puzzles, %d configurations, pareto front:\n\n", len(bs), len(costs))
+	fmt.Fprintf(w, "depth step width_div min_width descending %12s %12s %9s\n", "total", "worst", "nodes")
+	for _, c := range front {
+		p := c.params
+		fmt.Fprintf(w, "%5d %4d %9d %9d %10t %12v %12v %9d\n", p.Depth, p.Step, p.WidthDiv, p.MinWidth, p.Descending,
+			c.total.Round(time.Microsecond), c.worst.Round(time.Microsecond), c.nodes)
+	}
+
+	if out == "" {
+		return nil
+	}
+	return writeFile(out, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(front[0].params)
+	})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/phaul/sudoku/board"
+	"github.com/phaul/sudoku/formats"
+	"github.com/phaul/sudoku/solve"
+)
+
+// fewest clues a standard sudoku with a unique solution can have
+const minClues = 17
+
+// checks a single puzzle, returning the problem with it
+func verifyPuzzle(ctx context.Context, b board.Board) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if n := b.Clues(); n < minClues {
+		return fmt.Errorf("%w: %d, at least %d are needed", errTooFew, n, minClues)
+	}
+
+	r, err := solve.Auto(solve.Need{Count: true}).Solve(ctx, &b)
+	if err != nil {
+		return err
+	}
+	return r.Err()
+}
+
+// checks every puzzle of the sdm or puzzle bank files, reporting problems to w
+//
+// returns the number of puzzles with problems
+func verify(ctx context.Context, w io.Writer, files []string) (int, error) {
+	bad := 0
+
+	for _, fn := range files {
+		err := readPuzzles(fn, func(e formats.Entry, b board.Board, err error) error {
+			if err == nil {
+				err = verifyPuzzle(ctx, b)
+			}
+			if ctx.Err() != nil {
+				return err
+			}
+			if err != nil {
+				fmt.Fprintf(w, "%s:%d: %s\n", e.File, e.Line, err)
+				bad++
+			}
+			return nil
+		})
+		if err != nil {
+			return bad, err
+		}
+	}
+	return bad, nil
+}